@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-people-api/auth"
+	"go-people-api/config"
+	"go-people-api/db"
+)
+
+const apiKeyUsage = `usage:
+  go-people-api apikey issue -user <user_id> [-name <name>] [-role admin] [-scopes people:read,...] [-ttl 720h]
+  go-people-api apikey list
+  go-people-api apikey revoke -id <id>`
+
+// runAPIKeyCommand выпускает, показывает и отзывает ключи API из командной строки;
+// так выпускается первый ключ, пока ни одного ещё нет. Возвращает код завершения.
+func runAPIKeyCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, apiKeyUsage)
+		return 2
+	}
+	if err := connectDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "database:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys := auth.NewAPIKeys(auth.NewPostgresKeyStore(db.DB))
+	flags := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+
+	var result interface{}
+	switch args[0] {
+	case "issue":
+		userID := flags.String("user", "", "user_id, которому выдаётся ключ")
+		name := flags.String("name", "", "описание ключа")
+		role := flags.String("role", "", "роль: reader, operator или admin")
+		scopeList := flags.String("scopes", "", "права через запятую")
+		ttl := flags.Duration("ttl", 0, "срок действия, 0 — бессрочно")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if *userID == "" || (*role == "" && *scopeList == "") {
+			fmt.Fprintln(os.Stderr, "apikey issue: -user and -role or -scopes are required")
+			return 2
+		}
+		scopes, err := auth.ResolveScopes(splitList(*role), splitList(*scopeList))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "apikey issue:", err)
+			return 2
+		}
+		issued, err := keys.Issue(ctx, *userID, *name, scopes, *ttl)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "apikey issue:", err)
+			return 1
+		}
+		result = issued
+
+	case "list":
+		list, err := keys.List(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "apikey list:", err)
+			return 1
+		}
+		result = list
+
+	case "revoke":
+		id := flags.Int64("id", 0, "id ключа")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := keys.Revoke(ctx, *id); err != nil {
+			fmt.Fprintln(os.Stderr, "apikey revoke:", err)
+			return 1
+		}
+		result = map[string]interface{}{"status": "revoked", "id": *id}
+
+	default:
+		fmt.Fprintln(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	return printJSON(result)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-people-api/log"
+	"go-people-api/models"
+)
+
+// ErrKeyNotFound ключ не найден или уже отозван
+var ErrKeyNotFound = errors.New("api key not found")
+
+// Ключ имеет вид pk_<12 hex>_<64 hex>; первая часть (prefix) хранится открыто
+// и служит для поиска, от ключа целиком хранится SHA-256
+const (
+	keyPrefix      = "pk_"
+	prefixBytes    = 6
+	secretBytes    = 32
+	touchThreshold = time.Minute
+)
+
+// KeyStore хранилище ключей API
+type KeyStore interface {
+	// Create сохраняет ключ и заполняет ID и CreatedAt
+	Create(ctx context.Context, key *models.APIKey, hash string) error
+	// FindByPrefix возвращает ключ и его хэш; ErrKeyNotFound, если ключа нет
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, string, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	// Revoke отзывает ключ; ErrKeyNotFound, если ключа нет или он уже отозван
+	Revoke(ctx context.Context, id int64) error
+	Touch(ctx context.Context, id int64, at time.Time) error
+}
+
+// APIKeys выпускает, проверяет и отзывает ключи API
+type APIKeys struct {
+	store KeyStore
+	now   func() time.Time
+}
+
+func NewAPIKeys(store KeyStore) *APIKeys {
+	return &APIKeys{store: store, now: time.Now}
+}
+
+// IsAPIKey сообщает, похоже ли значение на ключ API (а не на JWT)
+func IsAPIKey(value string) bool {
+	return strings.HasPrefix(value, keyPrefix)
+}
+
+// Issue выпускает ключ для userID с правами scopes; ttl 0 — бессрочный ключ
+func (k *APIKeys) Issue(ctx context.Context, userID, name string, scopes []string, ttl time.Duration) (*models.IssuedAPIKey, error) {
+	prefix, err := randomHex(prefixBytes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &models.IssuedAPIKey{
+		Key: keyPrefix + prefix + "_" + secret,
+		APIKey: models.APIKey{
+			UserID: userID,
+			Name:   name,
+			Prefix: keyPrefix + prefix,
+			Scopes: scopes,
+		},
+	}
+	if ttl > 0 {
+		expiresAt := k.now().Add(ttl)
+		issued.ExpiresAt = &expiresAt
+	}
+
+	if err := k.store.Create(ctx, &issued.APIKey, hashKey(issued.Key)); err != nil {
+		return nil, fmt.Errorf("failed to store api key: %w", err)
+	}
+	return issued, nil
+}
+
+// Authenticate проверяет ключ; ошибки хранилища возвращаются как есть
+func (k *APIKeys) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	prefix, ok := splitKey(key)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	stored, hash, err := k.store.FindByPrefix(ctx, prefix)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(hashKey(key))) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	now := k.now()
+	if stored.RevokedAt != nil || (stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	// last_used_at обновляется не чаще раза в минуту, чтобы не писать в базу на каждый запрос
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= touchThreshold {
+		if err := k.store.Touch(ctx, stored.ID, now); err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Failed to update api key last_used_at")
+		}
+	}
+
+	return &Principal{
+		UserID: stored.UserID,
+		Method: MethodAPIKey,
+		KeyID:  stored.ID,
+		Scopes: stored.Scopes,
+	}, nil
+}
+
+func (k *APIKeys) List(ctx context.Context) ([]models.APIKey, error) {
+	return k.store.List(ctx)
+}
+
+func (k *APIKeys) Revoke(ctx context.Context, id int64) error {
+	return k.store.Revoke(ctx, id)
+}
+
+// splitKey проверяет формат ключа и возвращает его открытую часть
+func splitKey(key string) (string, bool) {
+	if !IsAPIKey(key) {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(key, keyPrefix), "_")
+	if !ok || len(prefix) != 2*prefixBytes || len(secret) != 2*secretBytes {
+		return "", false
+	}
+	return keyPrefix + prefix, true
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-people-api/requestid"
+)
+
+var (
+	// ErrNoCredentials запрос не содержит ни ключа, ни токена
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials ключ или токен неизвестен, отозван или просрочен
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal аутентифицированный клиент
+type Principal struct {
+	UserID string
+	Method string
+	// KeyID заполняется для ключей API
+	KeyID  int64
+	Scopes []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal сохраняет клиента в контексте, а его user_id — через requestid.WithUserID,
+// откуда его читают log.WithContext и история изменений
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey{}, principal)
+	return requestid.WithUserID(ctx, principal.UserID)
+}
+
+// FromContext возвращает клиента, сохранённого WithPrincipal
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Authenticator проверяет ключи API и JWT; любой из способов может быть не настроен
+type Authenticator struct {
+	keys *APIKeys
+	jwt  *JWTVerifier
+}
+
+func NewAuthenticator(keys *APIKeys, jwt *JWTVerifier) *Authenticator {
+	return &Authenticator{keys: keys, jwt: jwt}
+}
+
+// Authenticate читает X-API-Key или Authorization: Bearer.
+// Bearer-значение с префиксом ключа API проверяется как ключ, остальные — как JWT.
+func (a *Authenticator) Authenticate(ctx context.Context, r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.authenticateKey(ctx, key)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, ErrNoCredentials
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrInvalidCredentials
+	}
+	token = strings.TrimSpace(token)
+
+	if IsAPIKey(token) {
+		return a.authenticateKey(ctx, token)
+	}
+	if a.jwt == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return a.jwt.Verify(token)
+}
+
+func (a *Authenticator) authenticateKey(ctx context.Context, key string) (*Principal, error) {
+	if a.keys == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return a.keys.Authenticate(ctx, key)
+}
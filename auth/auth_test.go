@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func setupAuthRouter(t *testing.T, authenticator *Authenticator) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware(authenticator))
+	r.GET("/whoami", func(c *gin.Context) {
+		principal, _ := FromContext(c.Request.Context())
+		c.String(http.StatusOK, "%v|%s", requestid.UserIDFromContext(c.Request.Context()), principal.Method)
+	})
+	return r
+}
+
+func doAuthRequest(r *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	keys := NewAPIKeys(NewMemoryKeyStore())
+	r := setupAuthRouter(t, NewAuthenticator(keys, nil))
+	ctx := context.Background()
+
+	issued, err := keys.Issue(ctx, "alice", "ci", Roles["reader"], 0)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	if w := doAuthRequest(r, "", ""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("no credentials: expected 401 with challenge, got %d", w.Code)
+	}
+	if w := doAuthRequest(r, "X-API-Key", issued.Key); w.Code != http.StatusOK || w.Body.String() != "alice|api_key" {
+		t.Errorf("X-API-Key: got %d %q", w.Code, w.Body.String())
+	}
+	if w := doAuthRequest(r, "Authorization", "Bearer "+issued.Key); w.Code != http.StatusOK {
+		t.Errorf("bearer api key: got %d", w.Code)
+	}
+	// Последний символ меняется на другой, иначе ключ мог бы остаться прежним
+	wrong := issued.Key[:len(issued.Key)-1] + "0"
+	if strings.HasSuffix(issued.Key, "0") {
+		wrong = issued.Key[:len(issued.Key)-1] + "1"
+	}
+	if w := doAuthRequest(r, "X-API-Key", wrong); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: expected 401, got %d", w.Code)
+	}
+
+	list, _ := keys.List(ctx)
+	if len(list) != 1 || list[0].LastUsedAt == nil {
+		t.Errorf("expected last_used_at to be set, got %+v", list)
+	}
+
+	if err := keys.Revoke(ctx, issued.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if w := doAuthRequest(r, "X-API-Key", issued.Key); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: expected 401, got %d", w.Code)
+	}
+	if err := keys.Revoke(ctx, issued.ID); err != ErrKeyNotFound {
+		t.Errorf("second revoke: expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestMiddleware_ExpiredAPIKey(t *testing.T) {
+	keys := NewAPIKeys(NewMemoryKeyStore())
+	r := setupAuthRouter(t, NewAuthenticator(keys, nil))
+
+	issued, _ := keys.Issue(context.Background(), "bob", "", nil, time.Hour)
+	keys.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	if w := doAuthRequest(r, "X-API-Key", issued.Key); w.Code != http.StatusUnauthorized {
+		t.Errorf("expired key: expected 401, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_JWT(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := NewJWTVerifier(JWTConfig{
+		HMACSecret:   secret,
+		RSAPublicKey: &rsaKey.PublicKey,
+		Issuer:       "people-idp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := setupAuthRouter(t, NewAuthenticator(nil, verifier))
+
+	valid := jwt.RegisteredClaims{
+		Subject:   "carol",
+		Issuer:    "people-idp",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	foreign := valid
+	foreign.Issuer = "other"
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"HS256", signToken(t, jwt.SigningMethodHS256, secret, valid), http.StatusOK},
+		{"RS256", signToken(t, jwt.SigningMethodRS256, rsaKey, valid), http.StatusOK},
+		{"wrong secret", signToken(t, jwt.SigningMethodHS256, []byte("other"), valid), http.StatusUnauthorized},
+		{"HS512 not allowed", signToken(t, jwt.SigningMethodHS512, secret, valid), http.StatusUnauthorized},
+		{"expired", signToken(t, jwt.SigningMethodHS256, secret, expired), http.StatusUnauthorized},
+		{"wrong issuer", signToken(t, jwt.SigningMethodHS256, secret, foreign), http.StatusUnauthorized},
+		{"no exp", signToken(t, jwt.SigningMethodHS256, secret, noExpiry), http.StatusUnauthorized},
+		{"garbage", "not-a-token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAuthRequest(r, "Authorization", "Bearer "+tt.token)
+			if w.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code == http.StatusOK && w.Body.String() != "carol|jwt" {
+				t.Errorf("unexpected principal %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_RejectsHMACWithoutSecret(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	verifier, err := NewJWTVerifier(JWTConfig{RSAPublicKey: &rsaKey.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Токен, подписанный пустым HMAC-ключом, не должен приниматься, если HS256 не настроен
+	token := signToken(t, jwt.SigningMethodHS256, []byte{}, jwt.RegisteredClaims{
+		Subject:   "mallory",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	if _, err := verifier.Verify(token); err != ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if _, err := NewJWTVerifier(JWTConfig{}); err == nil {
+		t.Error("expected error without keys")
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier, _ := NewJWTVerifier(JWTConfig{HMACSecret: secret})
+	keys := NewAPIKeys(NewMemoryKeyStore())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(NewAuthenticator(keys, verifier)))
+	r.GET("/people", RequireScopes(ScopePeopleRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE("/people", RequireScopes(ScopePeopleDelete), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	reader, _ := keys.Issue(context.Background(), "analyst", "", Roles["reader"], 0)
+	token := func(claims tokenClaims) string {
+		claims.Subject = "op"
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		return "Bearer " + signToken(t, jwt.SigningMethodHS256, secret, claims)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		method string
+		code   int
+	}{
+		{"reader key reads", "X-API-Key", reader.Key, http.MethodGet, http.StatusOK},
+		{"reader key deletes", "X-API-Key", reader.Key, http.MethodDelete, http.StatusForbidden},
+		{"scope claim", "Authorization", token(tokenClaims{Scope: "openid people:read"}), http.MethodGet, http.StatusOK},
+		{"scp claim", "Authorization", token(tokenClaims{Scp: []string{"people:delete"}}), http.MethodDelete, http.StatusOK},
+		{"operator role", "Authorization", token(tokenClaims{Roles: []string{"operator"}}), http.MethodDelete, http.StatusForbidden},
+		{"admin role", "Authorization", token(tokenClaims{Roles: []string{"admin"}}), http.MethodDelete, http.StatusOK},
+		{"no scopes", "Authorization", token(tokenClaims{}), http.MethodGet, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/people", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code == http.StatusForbidden && !strings.Contains(w.Body.String(), `"forbidden"`) {
+				t.Errorf("expected forbidden error code, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResolveScopes(t *testing.T) {
+	scopes, err := ResolveScopes([]string{"operator"}, []string{ScopePeopleDelete, ScopePeopleRead})
+	if err != nil || strings.Join(scopes, " ") != "people:delete people:read people:write" {
+		t.Errorf("unexpected scopes %v, err %v", scopes, err)
+	}
+	if _, err := ResolveScopes([]string{"superuser"}, nil); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	if _, err := ResolveScopes(nil, []string{"people:purge"}); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig параметры проверки токенов; должен быть задан хотя бы один ключ.
+// Алгоритм токена принимается, только если для него настроен ключ.
+type JWTConfig struct {
+	// HMACSecret ключ для HS256
+	HMACSecret []byte
+	// RSAPublicKey открытый ключ для RS256
+	RSAPublicKey *rsa.PublicKey
+	// Issuer и Audience проверяются, если заданы
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// JWTVerifier проверяет подпись и срок действия bearer-токенов; user_id берётся из sub,
+// права — из scope (строка через пробел, как в OAuth 2.0), scp и roles
+type JWTVerifier struct {
+	config JWTConfig
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	var methods []string
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.RSAPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("jwt: neither HS256 secret nor RS256 public key configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTVerifier{config: config, parser: jwt.NewParser(options...)}, nil
+}
+
+// ParseRSAPublicKey разбирает открытый ключ RS256 в формате PEM
+func ParseRSAPublicKey(pem []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+	Roles []string `json:"roles"`
+}
+
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	var claims tokenClaims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.key); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if claims.Subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	scopes := append(strings.Fields(claims.Scope), claims.Scp...)
+	return &Principal{
+		UserID: claims.Subject,
+		Method: MethodJWT,
+		Scopes: claimScopes(claims.Roles, scopes),
+	}, nil
+}
+
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.config.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		return v.config.RSAPublicKey, nil
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-people-api/models"
+)
+
+// MemoryKeyStore хранит ключи API в памяти процесса (для тестов)
+type MemoryKeyStore struct {
+	mu     sync.Mutex
+	keys   []models.APIKey
+	hashes map[int64]string
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{hashes: make(map[int64]string)}
+}
+
+func (s *MemoryKeyStore) Create(_ context.Context, key *models.APIKey, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key.ID = int64(len(s.keys) + 1)
+	key.CreatedAt = time.Now()
+	s.keys = append(s.keys, *key)
+	s.hashes[key.ID] = hash
+	return nil
+}
+
+func (s *MemoryKeyStore) FindByPrefix(_ context.Context, prefix string) (*models.APIKey, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.Prefix == prefix {
+			return &key, s.hashes[key.ID], nil
+		}
+	}
+	return nil, "", ErrKeyNotFound
+}
+
+func (s *MemoryKeyStore) List(_ context.Context) ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]models.APIKey{}, s.keys...), nil
+}
+
+func (s *MemoryKeyStore) Revoke(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.find(id)
+	if key == nil || key.RevokedAt != nil {
+		return ErrKeyNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+func (s *MemoryKeyStore) Touch(_ context.Context, id int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key := s.find(id); key != nil {
+		key.LastUsedAt = &at
+	}
+	return nil
+}
+
+func (s *MemoryKeyStore) find(id int64) *models.APIKey {
+	for i := range s.keys {
+		if s.keys[i].ID == id {
+			return &s.keys[i]
+		}
+	}
+	return nil
+}
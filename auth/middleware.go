@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware пропускает только аутентифицированные запросы и кладёт клиента в контекст запроса
+func Middleware(authenticator *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		principal, err := authenticator.Authenticate(ctx, c.Request)
+		if err != nil {
+			if !errors.Is(err, ErrNoCredentials) && !errors.Is(err, ErrInvalidCredentials) {
+				log.WithContext(ctx).WithError(err).Error("Failed to authenticate request")
+				c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+					Error:     "auth_error",
+					Message:   "Failed to verify credentials",
+					RequestID: requestid.FromContext(c.Request.Context()),
+				})
+				return
+			}
+
+			message := "Authentication required"
+			if errors.Is(err, ErrInvalidCredentials) {
+				message = "Invalid or expired credentials"
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="people-api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:     "unauthorized",
+				Message:   message,
+				RequestID: requestid.FromContext(c.Request.Context()),
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithPrincipal(ctx, principal))
+		c.Set("user_id", principal.UserID)
+		c.Next()
+	}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/lib/pq"
+)
+
+// PostgresKeyStore хранит ключи API в таблице api_keys
+type PostgresKeyStore struct {
+	db *sql.DB
+}
+
+func NewPostgresKeyStore(db *sql.DB) *PostgresKeyStore {
+	return &PostgresKeyStore{db: db}
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, scopes, created_at, expires_at, last_used_at, revoked_at"
+
+func (s *PostgresKeyStore) Create(ctx context.Context, key *models.APIKey, hash string) error {
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (user_id, name, prefix, scopes, key_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, key.UserID, key.Name, key.Prefix, pq.Array(key.Scopes), hash, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (s *PostgresKeyStore) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, string, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+apiKeyColumns+", key_hash FROM api_keys WHERE prefix = $1", prefix)
+
+	var hash string
+	key, err := scanAPIKey(row, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return key, hash, nil
+}
+
+func (s *PostgresKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *PostgresKeyStore) Revoke(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (s *PostgresKeyStore) Touch(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $2 WHERE id = $1", id, at)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner, extra ...interface{}) (*models.APIKey, error) {
+	var key models.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	dest := append([]interface{}{
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedAt,
+		&expiresAt, &lastUsedAt, &revokedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	key.ExpiresAt = nullTime(expiresAt)
+	key.LastUsedAt = nullTime(lastUsedAt)
+	key.RevokedAt = nullTime(revokedAt)
+	return &key, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go-people-api/models"
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ScopePeopleRead   = "people:read"
+	ScopePeopleWrite  = "people:write"
+	ScopePeopleDelete = "people:delete"
+	// ScopeAdmin доступ к /admin: ключи API, кэш и провайдеры обогащения
+	ScopeAdmin = "admin"
+)
+
+// Roles наборы прав, которые можно выдать одним именем
+var Roles = map[string][]string{
+	"reader":   {ScopePeopleRead},
+	"operator": {ScopePeopleRead, ScopePeopleWrite},
+	"admin":    {ScopePeopleRead, ScopePeopleWrite, ScopePeopleDelete, ScopeAdmin},
+}
+
+var knownScopes = map[string]bool{
+	ScopePeopleRead:   true,
+	ScopePeopleWrite:  true,
+	ScopePeopleDelete: true,
+	ScopeAdmin:        true,
+}
+
+// ResolveScopes раскрывает роли и объединяет их с явно заданными правами.
+// Неизвестная роль или право — ошибка: так опечатка при выпуске ключа не превращается в ключ без прав.
+func ResolveScopes(roles, scopes []string) ([]string, error) {
+	set := make(map[string]bool)
+	for _, role := range roles {
+		granted, ok := Roles[role]
+		if !ok {
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+		for _, scope := range granted {
+			set[scope] = true
+		}
+	}
+	for _, scope := range scopes {
+		if !knownScopes[scope] {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		set[scope] = true
+	}
+	return sortedScopes(set), nil
+}
+
+// claimScopes как ResolveScopes, но неизвестные роли и права из токена пропускаются:
+// провайдер удостоверений может выдавать права и для других сервисов
+func claimScopes(roles, scopes []string) []string {
+	set := make(map[string]bool)
+	for _, role := range roles {
+		for _, scope := range Roles[role] {
+			set[scope] = true
+		}
+	}
+	for _, scope := range scopes {
+		if knownScopes[scope] {
+			set[scope] = true
+		}
+	}
+	return sortedScopes(set)
+}
+
+func sortedScopes(set map[string]bool) []string {
+	scopes := make([]string, 0, len(set))
+	for scope := range set {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// HasScope сообщает, выдано ли клиенту право
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScopes пропускает запрос, только если у клиента есть все перечисленные права.
+// Без клиента в контексте (аутентификация отключена) запрос пропускается.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		var missing []string
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:     "forbidden",
+				Message:   "Insufficient permissions",
+				Details:   "missing scopes: " + strings.Join(missing, " "),
+				RequestID: requestid.FromContext(c.Request.Context()),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go-people-api/config"
+)
+
+const configUsage = `usage:
+  go-people-api config print [-file config.yaml]
+  go-people-api config check [-file config.yaml]`
+
+// runConfigCommand показывает действующую конфигурацию (секреты скрыты) или только
+// проверяет её. Ошибки конфигурации выводятся все сразу; код завершения 1.
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "print" && args[0] != "check") {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	file := flags.String("file", "", "файл конфигурации YAML или TOML (по умолчанию CONFIG_FILE)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*file)
+	if args[0] == "print" {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+	if args[0] == "check" {
+		fmt.Println("configuration is valid")
+	}
+	return 0
+}
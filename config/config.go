@@ -0,0 +1,363 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go-people-api/db"
+	"go-people-api/jobs"
+	"go-people-api/log"
+	"go-people-api/ratelimit"
+	"go-people-api/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config настройки сервиса. Тег env — имя переменной окружения (для вложенных
+// структур — префикс), secret — значение скрывается в `config print`.
+type Config struct {
+	Server     ServerConfig
+	DB         db.Config
+	Log        log.Config
+	Tracing    TracingConfig
+	Auth       AuthConfig
+	RateLimit  RateLimitConfig
+	Enrichment EnrichmentConfig
+	Jobs       JobsConfig
+	People     PeopleConfig
+	Readiness  ReadinessConfig
+
+	// providerPolicies переопределения ENRICHMENT_<NAME>_* по имени провайдера в верхнем регистре
+	providerPolicies map[string]PolicyConfig
+	// sources откуда взято каждое значение, для `config print`
+	sources map[string]string
+}
+
+type ServerConfig struct {
+	Port                   int           `env:"PORT"`
+	GinMode                string        `env:"GIN_MODE"`
+	ShutdownReadinessDelay time.Duration `env:"SHUTDOWN_READINESS_DELAY"`
+	ShutdownTimeout        time.Duration `env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies адреса и подсети прокси, которым доверяется X-Forwarded-For;
+	// по умолчанию никому, и IP клиента — адрес соединения
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+}
+
+type TracingConfig struct {
+	// Exporter otlp, stdout или none; остальные OTEL_* SDK читает из окружения сам
+	Exporter string `env:"OTEL_TRACES_EXPORTER"`
+}
+
+type AuthConfig struct {
+	Enabled               bool          `env:"AUTH_ENABLED"`
+	JWTHS256Secret        string        `env:"AUTH_JWT_HS256_SECRET" secret:"true"`
+	JWTRS256PublicKeyFile string        `env:"AUTH_JWT_RS256_PUBLIC_KEY_FILE"`
+	JWTIssuer             string        `env:"AUTH_JWT_ISSUER"`
+	JWTAudience           string        `env:"AUTH_JWT_AUDIENCE"`
+	JWTLeeway             time.Duration `env:"AUTH_JWT_LEEWAY"`
+}
+
+type RateLimitConfig struct {
+	Store   string          `env:"RATE_LIMIT_STORE"`
+	IP      ratelimit.Limit `env:"RATE_LIMIT_IP"`
+	Default ratelimit.Limit `env:"RATE_LIMIT_DEFAULT"`
+	Create  ratelimit.Limit `env:"RATE_LIMIT_CREATE"`
+	Bulk    ratelimit.Limit `env:"RATE_LIMIT_BULK"`
+}
+
+type EnrichmentConfig struct {
+	Providers string `env:"ENRICHMENT_PROVIDERS"`
+	// ProviderKinds форматы API, объявленные без кода: kind=путь:поле;...
+	ProviderKinds  string `env:"ENRICHMENT_PROVIDER_KINDS"`
+	AgeAPI         string `env:"AGE_API"`
+	GenderAPI      string `env:"GENDER_API"`
+	NationalityAPI string `env:"NATIONALITY_API"`
+	// Policy политика по умолчанию; для провайдера переопределяется ENRICHMENT_<NAME>_*
+	Policy              PolicyConfig  `env:"ENRICHMENT_"`
+	QuotaWarnThresholds []float64     `env:"ENRICHMENT_QUOTA_WARN_THRESHOLDS"`
+	CacheSize           int           `env:"ENRICHMENT_CACHE_SIZE"`
+	CacheTTL            time.Duration `env:"ENRICHMENT_CACHE_TTL"`
+	CacheNegativeTTL    time.Duration `env:"ENRICHMENT_CACHE_NEGATIVE_TTL"`
+	CachePersistent     bool          `env:"ENRICHMENT_CACHE_PERSISTENT"`
+	Async               bool          `env:"ENRICHMENT_ASYNC"`
+}
+
+// PolicyConfig повторы и автомат размыкания провайдера
+type PolicyConfig struct {
+	RetryAttempts    int           `env:"RETRY_ATTEMPTS"`
+	RetryBaseDelay   time.Duration `env:"RETRY_BASE_DELAY"`
+	RetryMaxDelay    time.Duration `env:"RETRY_MAX_DELAY"`
+	AttemptTimeout   time.Duration `env:"ATTEMPT_TIMEOUT"`
+	BreakerThreshold int           `env:"BREAKER_THRESHOLD"`
+	BreakerTimeout   time.Duration `env:"BREAKER_TIMEOUT"`
+	QuotaBackoff     time.Duration `env:"QUOTA_BACKOFF"`
+}
+
+type JobsConfig struct {
+	Workers      int           `env:"ENRICHMENT_WORKERS"`
+	PollInterval time.Duration `env:"ENRICHMENT_POLL_INTERVAL"`
+	JobTimeout   time.Duration `env:"ENRICHMENT_JOB_TIMEOUT"`
+	RetryBase    time.Duration `env:"ENRICHMENT_JOB_RETRY_BASE"`
+	RetryMax     time.Duration `env:"ENRICHMENT_JOB_RETRY_MAX"`
+	MaxAttempts  int           `env:"ENRICHMENT_JOB_MAX_ATTEMPTS"`
+}
+
+type PeopleConfig struct {
+	Retention     time.Duration `env:"PEOPLE_RETENTION"`
+	PurgeInterval time.Duration `env:"PEOPLE_PURGE_INTERVAL"`
+}
+
+type ReadinessConfig struct {
+	DBTimeout       time.Duration `env:"READINESS_DB_TIMEOUT"`
+	CheckProviders  bool          `env:"READINESS_CHECK_PROVIDERS"`
+	ProviderTimeout time.Duration `env:"READINESS_PROVIDER_TIMEOUT"`
+}
+
+// Default значения по умолчанию, на которые накладываются файл, .env и окружение
+func Default() *Config {
+	policy := services.DefaultProviderPolicy
+	worker := jobs.DefaultWorkerConfig
+	return &Config{
+		Server: ServerConfig{
+			Port:                   8086,
+			ShutdownReadinessDelay: 5 * time.Second,
+			ShutdownTimeout:        30 * time.Second,
+		},
+		DB: db.Config{
+			Port:            5432,
+			ApplicationName: "go-people-api",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: 2 * time.Minute,
+		},
+		Log: log.Config{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter: "none",
+		},
+		Auth: AuthConfig{
+			Enabled:   true,
+			JWTLeeway: 30 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Store:   "memory",
+			IP:      ratelimit.Limit{Requests: 1200, Period: time.Minute},
+			Default: ratelimit.Limit{Requests: 600, Period: time.Minute},
+			Create:  ratelimit.Limit{Requests: 60, Period: time.Minute},
+			Bulk:    ratelimit.Limit{Requests: 10, Period: time.Minute},
+		},
+		Enrichment: EnrichmentConfig{
+			Policy: PolicyConfig{
+				RetryAttempts:    policy.Retry.MaxAttempts,
+				RetryBaseDelay:   policy.Retry.BaseDelay,
+				RetryMaxDelay:    policy.Retry.MaxDelay,
+				AttemptTimeout:   policy.Retry.AttemptTimeout,
+				BreakerThreshold: policy.Breaker.FailureThreshold,
+				BreakerTimeout:   policy.Breaker.OpenTimeout,
+				QuotaBackoff:     policy.QuotaBackoff,
+			},
+			QuotaWarnThresholds: services.DefaultQuotaThresholds,
+			CacheSize:           10000,
+			CacheTTL:            24 * time.Hour,
+			CacheNegativeTTL:    5 * time.Minute,
+		},
+		Jobs: JobsConfig{
+			PollInterval: worker.PollInterval,
+			JobTimeout:   worker.JobTimeout,
+			RetryBase:    worker.RetryBase,
+			RetryMax:     worker.RetryMax,
+			MaxAttempts:  5,
+		},
+		People: PeopleConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Readiness: ReadinessConfig{
+			DBTimeout:       2 * time.Second,
+			ProviderTimeout: 2 * time.Second,
+		},
+	}
+}
+
+// ProviderPolicy политика провайдера с учётом ENRICHMENT_<NAME>_*
+func (c *Config) ProviderPolicy(name string) services.ProviderPolicy {
+	policy, ok := c.providerPolicies[providerKey(name)]
+	if !ok {
+		policy = c.Enrichment.Policy
+	}
+	return policy.ProviderPolicy()
+}
+
+func (p PolicyConfig) ProviderPolicy() services.ProviderPolicy {
+	return services.ProviderPolicy{
+		Retry: services.RetryPolicy{
+			MaxAttempts:    p.RetryAttempts,
+			BaseDelay:      p.RetryBaseDelay,
+			MaxDelay:       p.RetryMaxDelay,
+			AttemptTimeout: p.AttemptTimeout,
+		},
+		Breaker: services.BreakerSettings{
+			FailureThreshold: p.BreakerThreshold,
+			OpenTimeout:      p.BreakerTimeout,
+		},
+		QuotaBackoff: p.QuotaBackoff,
+	}
+}
+
+// WorkerConfig настройки пула фонового обогащения
+func (j JobsConfig) WorkerConfig() jobs.WorkerConfig {
+	return jobs.WorkerConfig{
+		Workers:      j.Workers,
+		PollInterval: j.PollInterval,
+		JobTimeout:   j.JobTimeout,
+		RetryBase:    j.RetryBase,
+		RetryMax:     j.RetryMax,
+	}
+}
+
+func providerKey(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Validate проверяет значения целиком и возвращает все ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "PORT: must be between 1 and 65535, got %d", c.Server.Port)
+	check(oneOf(c.Server.GinMode, "", "debug", "release", "test"), "GIN_MODE: must be debug, release or test, got %q", c.Server.GinMode)
+	check(c.Server.ShutdownReadinessDelay >= 0, "SHUTDOWN_READINESS_DELAY: must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT: must be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES: invalid address or CIDR %q", proxy)
+	}
+
+	errs = append(errs, validateDB(c.DB)...)
+
+	_, err := logrus.ParseLevel(c.Log.Level)
+	check(err == nil, "LOG_LEVEL: unknown level %q", c.Log.Level)
+	check(oneOf(c.Log.Format, "text", "json"), "LOG_FORMAT: must be text or json, got %q", c.Log.Format)
+	check(oneOf(c.Tracing.Exporter, "", "none", "otlp", "stdout"), "OTEL_TRACES_EXPORTER: must be otlp, stdout or none, got %q", c.Tracing.Exporter)
+
+	check(c.Auth.JWTLeeway >= 0, "AUTH_JWT_LEEWAY: must not be negative")
+	check(oneOf(c.RateLimit.Store, "", "memory", "postgres", "off"), "RATE_LIMIT_STORE: must be memory, postgres or off, got %q", c.RateLimit.Store)
+
+	kinds, err := services.ParseProviderKinds(c.Enrichment.ProviderKinds)
+	check(err == nil, "ENRICHMENT_PROVIDER_KINDS: %v", err)
+	if c.Enrichment.Providers != "" {
+		_, err := services.ParseProviders(c.Enrichment.Providers, kinds)
+		check(err == nil, "ENRICHMENT_PROVIDERS: %v", err)
+	}
+	errs = append(errs, c.Enrichment.Policy.validate("ENRICHMENT_")...)
+	for name, policy := range c.providerPolicies {
+		errs = append(errs, policy.validate("ENRICHMENT_"+name+"_")...)
+	}
+	for _, t := range c.Enrichment.QuotaWarnThresholds {
+		check(t > 0 && t < 1, "ENRICHMENT_QUOTA_WARN_THRESHOLDS: %v is not between 0 and 1", t)
+	}
+	if c.Enrichment.CacheSize > 0 {
+		check(c.Enrichment.CacheTTL > 0, "ENRICHMENT_CACHE_TTL: must be positive")
+		check(c.Enrichment.CacheNegativeTTL > 0, "ENRICHMENT_CACHE_NEGATIVE_TTL: must be positive")
+	}
+
+	if c.Jobs.Workers > 0 {
+		check(c.Jobs.PollInterval > 0, "ENRICHMENT_POLL_INTERVAL: must be positive")
+		check(c.Jobs.JobTimeout > 0, "ENRICHMENT_JOB_TIMEOUT: must be positive")
+		check(c.Jobs.RetryBase > 0 && c.Jobs.RetryMax >= c.Jobs.RetryBase,
+			"ENRICHMENT_JOB_RETRY_BASE/ENRICHMENT_JOB_RETRY_MAX: must be positive and base <= max")
+		check(c.Jobs.MaxAttempts > 0, "ENRICHMENT_JOB_MAX_ATTEMPTS: must be positive")
+	}
+	check(c.Jobs.Workers >= 0, "ENRICHMENT_WORKERS: must not be negative")
+	check(!c.Enrichment.Async || c.Jobs.Workers > 0, "ENRICHMENT_ASYNC: requires ENRICHMENT_WORKERS > 0")
+
+	if c.People.Retention > 0 {
+		check(c.People.PurgeInterval > 0, "PEOPLE_PURGE_INTERVAL: must be positive")
+	}
+	check(c.Readiness.DBTimeout > 0, "READINESS_DB_TIMEOUT: must be positive")
+	check(c.Readiness.ProviderTimeout > 0, "READINESS_PROVIDER_TIMEOUT: must be positive")
+
+	return errors.Join(errs...)
+}
+
+func validateDB(c db.Config) []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if c.URL != "" {
+		_, err := c.DSN()
+		check(err == nil, "%v", err)
+	} else {
+		check(c.Host != "", "DB_HOST: required")
+		check(c.Port > 0 && c.Port < 65536, "DB_PORT: must be between 1 and 65535, got %d", c.Port)
+		check(c.User != "", "DB_USER: required")
+		// Без пароля можно войти только по клиентскому сертификату
+		check(c.Password != "" || c.SSLCert != "", "DB_PASSWORD: required")
+		check(c.Name != "", "DB_NAME: required")
+	}
+
+	check(oneOf(c.SSLMode, "", "disable", "require", "verify-ca", "verify-full"),
+		"DB_SSLMODE: must be disable, require, verify-ca or verify-full, got %q", c.SSLMode)
+	check((c.SSLCert == "") == (c.SSLKey == ""), "DB_SSLCERT/DB_SSLKEY: must be set together")
+	if c.SSLRootCert != "" || c.SSLCert != "" {
+		sslmode := c.SSLMode
+		if sslmode == "" && c.URL == "" {
+			sslmode = "disable"
+		}
+		check(sslmode != "disable", "DB_SSLMODE: certificates are configured but TLS is disabled")
+	}
+
+	check(c.StatementTimeout >= 0, "DB_STATEMENT_TIMEOUT: must not be negative")
+	check(c.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS: must not be negative")
+	check(c.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS: must not be negative")
+	check(c.MaxOpenConns == 0 || c.MaxIdleConns <= c.MaxOpenConns, "DB_MAX_IDLE_CONNS: must not exceed DB_MAX_OPEN_CONNS")
+	check(c.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME: must not be negative")
+	check(c.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME: must not be negative")
+	return errs
+}
+
+func (p PolicyConfig) validate(prefix string) []error {
+	var errs []error
+	if p.RetryAttempts < 1 {
+		errs = append(errs, fmt.Errorf("%sRETRY_ATTEMPTS: must be at least 1", prefix))
+	}
+	if p.RetryBaseDelay < 0 || p.RetryMaxDelay < p.RetryBaseDelay {
+		errs = append(errs, fmt.Errorf("%sRETRY_BASE_DELAY/%sRETRY_MAX_DELAY: must not be negative and base <= max", prefix, prefix))
+	}
+	if p.AttemptTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("%sATTEMPT_TIMEOUT: must be positive", prefix))
+	}
+	if p.BreakerThreshold < 1 {
+		errs = append(errs, fmt.Errorf("%sBREAKER_THRESHOLD: must be at least 1", prefix))
+	}
+	if p.BreakerTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("%sBREAKER_TIMEOUT: must be positive", prefix))
+	}
+	if p.QuotaBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("%sQUOTA_BACKOFF: must be positive", prefix))
+	}
+	return errs
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
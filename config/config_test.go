@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var requiredDB = map[string]string{
+	"DB_HOST": "localhost", "DB_USER": "postgres", "DB_PASSWORD": "s3cret", "DB_NAME": "people",
+}
+
+func TestBuild_Precedence(t *testing.T) {
+	file := map[string]string{"PORT": "9000", "LOG_LEVEL": "warn", "ENRICHMENT_CACHE_TTL": "1h"}
+	dotenv := map[string]string{"LOG_LEVEL": "debug", "ENRICHMENT_CACHE_TTL": "2h"}
+	env := map[string]string{"ENRICHMENT_CACHE_TTL": "3h", "RATE_LIMIT_CREATE": "5/s", "TRUSTED_PROXIES": "10.0.0.0/8, 192.0.2.1"}
+	for k, v := range requiredDB {
+		env[k] = v
+	}
+
+	cfg, err := build(file, dotenv, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Log.Level != "debug" || cfg.Enrichment.CacheTTL != 3*time.Hour {
+		t.Errorf("wrong precedence: port=%d level=%s ttl=%s", cfg.Server.Port, cfg.Log.Level, cfg.Enrichment.CacheTTL)
+	}
+	if cfg.RateLimit.Create.Requests != 5 || cfg.RateLimit.Create.Period != time.Second {
+		t.Errorf("expected 5/s create limit, got %+v", cfg.RateLimit.Create)
+	}
+	if strings.Join(cfg.Server.TrustedProxies, " ") != "10.0.0.0/8 192.0.2.1" {
+		t.Errorf("unexpected trusted proxies %q", cfg.Server.TrustedProxies)
+	}
+	if cfg.DB.Port != 5432 || cfg.sources["DB_PORT"] != SourceDefault || cfg.sources["PORT"] != SourceFile {
+		t.Errorf("unexpected sources %v", cfg.sources)
+	}
+}
+
+func TestBuild_ProviderPolicyOverrides(t *testing.T) {
+	env := map[string]string{"ENRICHMENT_RETRY_ATTEMPTS": "4", "ENRICHMENT_AGE_RETRY_ATTEMPTS": "1", "ENRICHMENT_AGE_QUOTA_BACKOFF": "5m", "ENRICHMENT_JOB_RETRY_BASE": "5s"}
+	for k, v := range requiredDB {
+		env[k] = v
+	}
+
+	cfg, err := build(nil, nil, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ProviderPolicy("age").Retry.MaxAttempts; got != 1 {
+		t.Errorf("expected age override, got %d", got)
+	}
+	if got := cfg.ProviderPolicy("age").QuotaBackoff; got != 5*time.Minute {
+		t.Errorf("expected age quota backoff override, got %s", got)
+	}
+	if got := cfg.ProviderPolicy("gender").QuotaBackoff; got != time.Minute {
+		t.Errorf("expected default quota backoff for gender, got %s", got)
+	}
+	if got := cfg.ProviderPolicy("gender").Retry.MaxAttempts; got != 4 {
+		t.Errorf("expected shared policy for gender, got %d", got)
+	}
+	if _, ok := cfg.providerPolicies["JOB"]; ok {
+		t.Error("ENRICHMENT_JOB_RETRY_BASE must not be treated as a provider override")
+	}
+}
+
+func TestBuild_ReportsAllErrors(t *testing.T) {
+	file := map[string]string{"DB_HOTS": "typo"}
+	env := map[string]string{
+		"PORT":                             "http",
+		"LOG_FORMAT":                       "xml",
+		"RATE_LIMIT_BULK":                  "often",
+		"ENRICHMENT_QUOTA_WARN_THRESHOLDS": "0.5,2",
+		"TRUSTED_PROXIES":                  "proxy.local",
+	}
+
+	_, err := build(file, nil, env)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"PORT: invalid integer", "LOG_FORMAT", "RATE_LIMIT_BULK", "ENRICHMENT_QUOTA_WARN_THRESHOLDS", "DB_HOST: required", "DB_PASSWORD: required", "DB_HOTS: unknown key", "TRUSTED_PROXIES"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestBuild_DatabaseURL(t *testing.T) {
+	env := map[string]string{"DATABASE_URL": "postgres://app@db.internal/people", "DB_SSLROOTCERT": "/etc/ca.pem"}
+	if _, err := build(nil, nil, env); err != nil {
+		t.Fatalf("DATABASE_URL must replace DB_* variables: %v", err)
+	}
+
+	env = map[string]string{"DB_SSLCERT": "/etc/client.crt", "DB_MAX_OPEN_CONNS": "2", "DB_MAX_IDLE_CONNS": "5"}
+	for k, v := range requiredDB {
+		env[k] = v
+	}
+	_, err := build(nil, nil, env)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"DB_SSLCERT/DB_SSLKEY", "TLS is disabled", "DB_MAX_IDLE_CONNS"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "port: 9000\ndb:\n  host: db.local\nenrichment:\n  quota_warn_thresholds: [0.5, 0.25]\n",
+		"config.toml": "port = 9000\n[db]\nhost = \"db.local\"\n[enrichment]\nquota_warn_thresholds = [0.5, 0.25]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		values, err := readFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if values["PORT"] != "9000" || values["DB_HOST"] != "db.local" || values["ENRICHMENT_QUOTA_WARN_THRESHOLDS"] != "0.5,0.25" {
+			t.Errorf("%s: unexpected values %v", name, values)
+		}
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	env := map[string]string{"AUTH_JWT_HS256_SECRET": "top-secret"}
+	for k, v := range requiredDB {
+		env[k] = v
+	}
+	cfg, err := build(nil, nil, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "top-secret") || strings.Contains(out.String(), "s3cret") {
+		t.Errorf("secrets leaked:\n%s", out.String())
+	}
+	for _, want := range []string{"DB_PASSWORD=" + redacted, "RATE_LIMIT_DEFAULT=600/m", "DB_HOST=localhost"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
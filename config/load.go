@@ -0,0 +1,324 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Источники значений в порядке возрастания приоритета
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+)
+
+// DotEnvFile файл с переменными для локальной разработки; его отсутствие не ошибка
+const DotEnvFile = ".env"
+
+// policyKeys окончания переменных, которые можно переопределить для отдельного провайдера
+var policyKeys = []string{"RETRY_ATTEMPTS", "RETRY_BASE_DELAY", "RETRY_MAX_DELAY", "ATTEMPT_TIMEOUT", "BREAKER_THRESHOLD", "BREAKER_TIMEOUT", "QUOTA_BACKOFF"}
+
+// Load собирает конфигурацию: значения по умолчанию, затем файл YAML/TOML (path или CONFIG_FILE),
+// затем .env, затем переменные окружения. Ключи файла — имена переменных в нижнем регистре,
+// вложенные таблицы склеиваются через "_" (db: {host: x} — то же, что DB_HOST).
+// Переменные из .env, которых нет в окружении, экспортируются в него, чтобы их видели
+// библиотеки, читающие окружение сами (OTEL_*). При ошибках возвращается конфигурация
+// с разобранными значениями и все ошибки сразу.
+func Load(path string) (*Config, error) {
+	dotenv, err := godotenv.Read(DotEnvFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Default(), fmt.Errorf("failed to read %s: %w", DotEnvFile, err)
+	}
+	env := environ()
+	for key, value := range dotenv {
+		if _, ok := env[key]; !ok {
+			_ = os.Setenv(key, value)
+		}
+	}
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	var file map[string]string
+	if path != "" {
+		if file, err = readFile(path); err != nil {
+			return Default(), err
+		}
+	}
+
+	return build(file, dotenv, env)
+}
+
+// build накладывает источники на значения по умолчанию; каждый следующий важнее предыдущего
+func build(file, dotenv, env map[string]string) (*Config, error) {
+	cfg := Default()
+	cfg.sources = map[string]string{}
+	cfg.providerPolicies = map[string]PolicyConfig{}
+
+	lookup := func(key string) (string, string, bool) {
+		if value, ok := env[key]; ok {
+			return value, SourceEnv, true
+		}
+		if value, ok := dotenv[key]; ok {
+			return value, SourceDotEnv, true
+		}
+		if value, ok := file[key]; ok {
+			return value, SourceFile, true
+		}
+		return "", SourceDefault, false
+	}
+
+	var errs []error
+	apply := func(fields []field) {
+		for _, f := range fields {
+			value, source, ok := lookup(f.key)
+			cfg.sources[f.key] = source
+			if !ok {
+				continue
+			}
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+			}
+		}
+	}
+
+	known := map[string]bool{"CONFIG_FILE": true}
+	for _, f := range fieldsOf(reflect.ValueOf(cfg).Elem(), "") {
+		known[f.key] = true
+	}
+	apply(fieldsOf(reflect.ValueOf(cfg).Elem(), ""))
+
+	// Переопределения политики накладываются на уже собранную политику по умолчанию
+	for _, name := range providerNames(file, dotenv, env) {
+		policy := cfg.Enrichment.Policy
+		fields := fieldsOf(reflect.ValueOf(&policy).Elem(), "ENRICHMENT_"+name+"_")
+		apply(fields)
+		for _, f := range fields {
+			known[f.key] = true
+		}
+		cfg.providerPolicies[name] = policy
+	}
+
+	for _, key := range sortedKeys(file) {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s: unknown key in config file", key))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// providerNames имена провайдеров, для которых заданы ENRICHMENT_<NAME>_*
+func providerNames(sources ...map[string]string) []string {
+	seen := map[string]bool{}
+	for _, source := range sources {
+		for key := range source {
+			rest, ok := strings.CutPrefix(key, "ENRICHMENT_")
+			if !ok {
+				continue
+			}
+			for _, suffix := range policyKeys {
+				if name, ok := strings.CutSuffix(rest, "_"+suffix); ok && name != "" {
+					seen[name] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func environ() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// readFile читает YAML (.yaml, .yml) или TOML (.toml) и разворачивает его в плоские ключи
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q: use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten(raw, "", values)
+	return values, nil
+}
+
+func flatten(raw map[string]interface{}, prefix string, out map[string]string) {
+	for key, value := range raw {
+		key = prefix + strings.ToUpper(key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(v, key+"_", out)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// field поле конфигурации с именем переменной
+type field struct {
+	key    string
+	secret bool
+	value  reflect.Value
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldsOf перечисляет поля с тегом env; вложенные структуры добавляют свой префикс
+func fieldsOf(v reflect.Value, prefix string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		tag := sf.Tag.Get("env")
+		if fv.Kind() == reflect.Struct && !reflect.PointerTo(sf.Type).Implements(textUnmarshalerType) {
+			fields = append(fields, fieldsOf(fv, prefix+tag)...)
+			continue
+		}
+		if tag == "" {
+			continue
+		}
+		fields = append(fields, field{key: prefix + tag, secret: sf.Tag.Get("secret") == "true", value: fv})
+	}
+	return fields
+}
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	v := f.value
+	// Пустое значение (DB_PORT=) оставляет значение по умолчанию; пустую строку можно задать только строковому полю
+	if raw == "" && v.Kind() != reflect.String {
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var values []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Float64:
+		var values []float64
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(item, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", item)
+			}
+			values = append(values, n)
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	v := f.value
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, _ := m.MarshalText()
+		return string(text)
+	}
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Float64:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = strconv.FormatFloat(v.Index(i).Float(), 'g', -1, 64)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
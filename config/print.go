@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// redacted замена секретов в выводе
+const redacted = "******"
+
+// Print выводит действующие значения в формате .env с источником каждого значения;
+// секреты (пароли, ключи подписи) скрываются
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	write := func(fields []field) {
+		for _, f := range fields {
+			value := f.String()
+			if f.secret && value != "" {
+				value = redacted
+			}
+			source := c.sources[f.key]
+			if source == "" {
+				source = SourceDefault
+			}
+			fmt.Fprintf(tw, "%s=%s\t# %s\n", f.key, value, source)
+		}
+	}
+
+	write(fieldsOf(reflect.ValueOf(c).Elem(), ""))
+	for _, name := range sortedKeys(c.providerPolicies) {
+		policy := c.providerPolicies[name]
+		write(fieldsOf(reflect.ValueOf(&policy).Elem(), "ENRICHMENT_"+name+"_"))
+	}
+	return tw.Flush()
+}
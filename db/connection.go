@@ -4,63 +4,171 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
-	DB   *sql.DB
-	once sync.Once
+	DB *sql.DB
+	mu sync.Mutex
 )
 
-func Init() error {
-	var initErr error
-	once.Do(func() {
-		requiredEnv := []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME"}
-		for _, env := range requiredEnv {
-			if os.Getenv(env) == "" {
-				initErr = fmt.Errorf("missing required environment variable: %s", env)
-				return
-			}
+// Config параметры подключения к PostgreSQL
+type Config struct {
+	// URL полная строка подключения (postgres://...); если задана, DB_HOST, DB_PORT,
+	// DB_USER, DB_PASSWORD и DB_NAME не используются
+	URL      string `env:"DATABASE_URL" secret:"true"`
+	Host     string `env:"DB_HOST"`
+	Port     int    `env:"DB_PORT"`
+	User     string `env:"DB_USER"`
+	Password string `env:"DB_PASSWORD" secret:"true"`
+	Name     string `env:"DB_NAME"`
+
+	// SSLMode disable, require, verify-ca или verify-full; пусто — sslmode из DATABASE_URL,
+	// а без него disable для DB_HOST и require (по умолчанию lib/pq) для DATABASE_URL
+	SSLMode string `env:"DB_SSLMODE"`
+	// SSLRootCert сертификат CA для verify-ca/verify-full
+	SSLRootCert string `env:"DB_SSLROOTCERT"`
+	// SSLCert и SSLKey клиентский сертификат и ключ
+	SSLCert string `env:"DB_SSLCERT"`
+	SSLKey  string `env:"DB_SSLKEY"`
+
+	// ApplicationName имя приложения в pg_stat_activity
+	ApplicationName string `env:"DB_APPLICATION_NAME"`
+	// StatementTimeout ограничение на один запрос на стороне сервера; 0 — без ограничения
+	StatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT"`
+
+	MaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// AutoMigrate применять встроенные миграции при запуске сервера
+	AutoMigrate bool `env:"DB_AUTO_MIGRATE"`
+}
+
+// DSN строка подключения для lib/pq. Параметры TLS, application_name и statement_timeout,
+// если заданы, накладываются поверх DATABASE_URL: при повторе ключа lib/pq берёт последний.
+func (c Config) DSN() (string, error) {
+	var opts []string
+	add := func(key, value string) {
+		if value != "" {
+			opts = append(opts, key+"="+quoteOption(value))
 		}
+	}
 
-		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_USER"),
-			os.Getenv("DB_PASSWORD"),
-			os.Getenv("DB_NAME"))
-
-		DB, initErr = sql.Open("postgres", dsn)
-		if initErr != nil {
-			initErr = fmt.Errorf("failed to open database connection: %w", initErr)
-			return
+	if c.URL != "" {
+		base, err := pq.ParseURL(c.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid DATABASE_URL: %w", err)
 		}
+		opts = append(opts, base)
+	} else {
+		add("host", c.Host)
+		add("port", strconv.Itoa(c.Port))
+		add("user", c.User)
+		add("password", c.Password)
+		add("dbname", c.Name)
+		if c.SSLMode == "" {
+			add("sslmode", "disable")
+		}
+	}
 
-		DB.SetMaxOpenConns(25)
-		DB.SetMaxIdleConns(5)
-		DB.SetConnMaxLifetime(5 * time.Minute)
-		DB.SetConnMaxIdleTime(2 * time.Minute)
+	add("sslmode", c.SSLMode)
+	add("sslrootcert", c.SSLRootCert)
+	add("sslcert", c.SSLCert)
+	add("sslkey", c.SSLKey)
+	add("application_name", c.ApplicationName)
+	if c.StatementTimeout > 0 {
+		// Неизвестные драйверу ключи lib/pq передаёт серверу как параметры сессии
+		add("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
+	return strings.Join(opts, " "), nil
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+// quoteOption экранирует значение для строки вида key='value'
+func quoteOption(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
 
-		if initErr = DB.PingContext(ctx); initErr != nil {
-			initErr = fmt.Errorf("database ping failed: %w", initErr)
-			_ = DB.Close()
-			DB = nil
-		}
-	})
+// Init открывает пул и проверяет подключение. После неудачи DB остаётся nil
+// и Init можно вызвать повторно; после успеха повторные вызовы ничего не делают.
+func Init(config Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if DB != nil {
+		return nil
+	}
 
-	return initErr
+	dsn, err := config.DSN()
+	if err != nil {
+		return err
+	}
+
+	// otelsql создаёт спан на каждый SQL-запрос, если трассировка включена
+	conn, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(attribute.String("db.system", "postgresql")),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+	conn.SetMaxIdleConns(config.MaxIdleConns)
+	conn.SetConnMaxLifetime(config.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := conn.PingContext(ctx); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+
+	DB = conn
+	return nil
 }
 
+// GetDB возвращает пул под той же блокировкой, под которой его меняют Init и Close,
+// поэтому его можно вызывать из проверок готовности во время остановки
 func GetDB() (*sql.DB, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	if DB == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
 	return DB, nil
 }
+
+// setDB подменяет пул под блокировкой; для тестов, которым не нужен Postgres
+func setDB(conn *sql.DB) {
+	mu.Lock()
+	defer mu.Unlock()
+	DB = conn
+}
+
+// Close закрывает пул соединений; вызывается последним при остановке сервера.
+// После Close DB равен nil, повторный вызов ничего не делает, а Init может открыть пул заново.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if DB == nil {
+		return nil
+	}
+	err := DB.Close()
+	DB = nil
+	return err
+}
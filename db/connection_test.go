@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigDSN(t *testing.T) {
+	dsn, err := Config{Host: "localhost", Port: 5432, User: "postgres", Password: `it's\secret`, Name: "people"}.DSN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `host='localhost' port='5432' user='postgres' password='it\'s\\secret' dbname='people' sslmode='disable'`
+	if dsn != want {
+		t.Errorf("got %s\nwant %s", dsn, want)
+	}
+
+	dsn, err = Config{
+		URL:              "postgres://app:pw@db.internal:6432/people?sslmode=require",
+		Host:             "ignored",
+		SSLMode:          "verify-full",
+		SSLRootCert:      "/etc/ca.pem",
+		ApplicationName:  "go-people-api",
+		StatementTimeout: 1500 * time.Millisecond,
+	}.DSN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(dsn, "ignored") || !strings.Contains(dsn, "host='db.internal'") {
+		t.Errorf("DATABASE_URL must replace DB_HOST: %s", dsn)
+	}
+	// Значения из конфигурации идут после URL и побеждают при разборе lib/pq
+	if strings.Index(dsn, "sslmode='verify-full'") < strings.Index(dsn, "sslmode='require'") {
+		t.Errorf("DB_SSLMODE must override URL sslmode: %s", dsn)
+	}
+	for _, part := range []string{"sslrootcert='/etc/ca.pem'", "application_name='go-people-api'", "statement_timeout='1500'"} {
+		if !strings.Contains(dsn, part) {
+			t.Errorf("dsn %s does not contain %s", dsn, part)
+		}
+	}
+
+	if _, err := (Config{URL: "mysql://localhost"}).DSN(); err == nil {
+		t.Error("expected error for non-postgres URL")
+	}
+}
+
+func TestClose_Twice(t *testing.T) {
+	// sql.Open не подключается к базе, пул можно закрыть без Postgres
+	conn, err := sql.Open("postgres", "host=localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setDB(conn)
+	if got, err := GetDB(); err != nil || got != conn {
+		t.Fatalf("expected pool from GetDB, got %v, %v", got, err)
+	}
+
+	// Проверка готовности может читать пул во время остановки; go test -race ловит гонку
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_, _ = GetDB()
+		}
+	}()
+	if err := Close(); err != nil {
+		t.Fatalf("first close: %v", err)
+	}
+	<-done
+	if _, err := GetDB(); err == nil {
+		t.Fatal("DB must be nil after Close")
+	}
+	if err := Close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+}
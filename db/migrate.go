@@ -1,33 +1,325 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-func ApplyMigrations() error {
-	// Получаем абсолютный путь до каталога с миграциями
-	dir, err := os.Getwd()
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockID ключ advisory-блокировки: пока одна реплика применяет миграции, остальные ждут
+const migrationLockID int64 = 0x70656f706c65 // "people"
+
+// ErrDirty база осталась в промежуточном состоянии после миграции, выполненной вне транзакции
+// (например, внешним migrate); нужно исправить схему и сбросить флаг dirty вручную
+var ErrDirty = errors.New("database schema is dirty")
+
+// Migration пара up/down-скриптов одной версии
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState применена ли миграция
+type MigrationState struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// MigrationStatus текущая версия схемы и состояние всех известных миграций
+type MigrationStatus struct {
+	Version    int64            `json:"version"`
+	Dirty      bool             `json:"dirty"`
+	Migrations []MigrationState `json:"migrations"`
+}
+
+// Migrator применяет миграции внутри процесса. Версия хранится в schema_migrations
+// в том же формате, что у golang-migrate, поэтому базы, размеченные внешним migrate,
+// продолжают работать. Каждая миграция и смена версии выполняются в одной транзакции.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator создаёт мигратор со встроенными в бинарник миграциями из db/migrations
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations читает файлы <version>_<name>.up.sql и <version>_<name>.down.sql
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, file := range files {
+		base := path.Base(file)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", base)
+		}
+
+		versionPart, name, ok := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name> file name", base)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up применяет все новые миграции и возвращает применённые
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.migrate(ctx, func(current int64) []step {
+		var steps []step
+		for _, mig := range m.migrations {
+			if mig.Version > current {
+				steps = append(steps, step{migration: mig, up: true})
+			}
+		}
+		return steps
+	})
+}
+
+// Down откатывает n последних применённых миграций
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of migrations to roll back must be positive")
+	}
+	return m.migrate(ctx, func(current int64) []step {
+		var steps []step
+		for i := len(m.migrations) - 1; i >= 0 && len(steps) < n; i-- {
+			if m.migrations[i].Version <= current {
+				steps = append(steps, step{migration: m.migrations[i]})
+			}
+		}
+		return steps
+	})
+}
+
+// Goto применяет или откатывает миграции до указанной версии; 0 — откатить все
+func (m *Migrator) Goto(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 && m.index(version) < 0 {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.migrate(ctx, func(current int64) []step {
+		var steps []step
+		if version >= current {
+			for _, mig := range m.migrations {
+				if mig.Version > current && mig.Version <= version {
+					steps = append(steps, step{migration: mig, up: true})
+				}
+			}
+			return steps
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if mig := m.migrations[i]; mig.Version <= current && mig.Version > version {
+				steps = append(steps, step{migration: mig})
+			}
+		}
+		return steps
+	})
+}
+
+// Status возвращает текущую версию и список миграций с отметкой о применении
+func (m *Migrator) Status(ctx context.Context) (MigrationStatus, error) {
+	if err := ensureTable(ctx, m.db); err != nil {
+		return MigrationStatus{}, err
+	}
+	version, dirty, err := readVersion(ctx, m.db)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+
+	status := MigrationStatus{Version: version, Dirty: dirty}
+	for _, mig := range m.migrations {
+		status.Migrations = append(status.Migrations, MigrationState{
+			Version: mig.Version,
+			Name:    mig.Name,
+			Applied: mig.Version <= version,
+		})
+	}
+	return status, nil
+}
+
+// step одна миграция в нужном направлении
+type step struct {
+	migration Migration
+	up        bool
+}
+
+// migrate берёт блокировку, читает версию и выполняет шаги, выбранные plan
+func (m *Migrator) migrate(ctx context.Context, plan func(current int64) []step) ([]Migration, error) {
+	// Advisory-блокировка принадлежит сессии, поэтому всё выполняется на одном соединении
+	conn, err := m.db.Conn(ctx)
 	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// DB_STATEMENT_TIMEOUT рассчитан на запросы API; миграции и ожидание блокировки не ограничиваются.
+	// RESET возвращает значение из параметров подключения, прежде чем соединение вернётся в пул.
+	if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(context.Background(), "RESET statement_timeout")
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("%w at version %d", ErrDirty, current)
+	}
+	if current != 0 && m.index(current) < 0 {
+		return nil, fmt.Errorf("database schema version %d is unknown to this build", current)
+	}
+
+	var done []Migration
+	for _, s := range plan(current) {
+		if err := m.apply(ctx, conn, s); err != nil {
+			return done, err
+		}
+		done = append(done, s.migration)
+	}
+	return done, nil
+}
+
+// apply выполняет скрипт и записывает новую версию в одной транзакции
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, s step) error {
+	script, target := s.migration.Up, s.migration.Version
+	if !s.up {
+		if s.migration.Down == "" {
+			return fmt.Errorf("migration %d_%s has no down script", s.migration.Version, s.migration.Name)
+		}
+		script, target = s.migration.Down, m.previous(s.migration.Version)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", s.migration.Version, s.migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "TRUNCATE schema_migrations"); err != nil {
 		return err
 	}
-	migrationsPath := filepath.Join(dir, "migrations")
+	if target > 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", target); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// previous версия перед указанной; 0 — миграций до неё нет
+func (m *Migrator) previous(version int64) int64 {
+	if i := m.index(version); i > 0 {
+		return m.migrations[i-1].Version
+	}
+	return 0
+}
+
+func (m *Migrator) index(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
 
-	cmd := exec.Command("migrate",
-		"-path", migrationsPath,
-		"-database", fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			os.Getenv("DB_USER"),
-			os.Getenv("DB_PASSWORD"),
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_NAME")),
-		"up")
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+func ensureTable(ctx context.Context, q execQuerier) error {
+	_, err := q.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		dirty BOOLEAN NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
 
-	return cmd.Run()
+// readVersion возвращает текущую версию; 0 — миграции ещё не применялись
+func readVersion(ctx context.Context, q execQuerier) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := q.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, dirty, nil
 }
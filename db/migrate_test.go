@@ -0,0 +1,59 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatalf("embedded migrations are invalid: %v", err)
+	}
+	if len(m.migrations) == 0 || m.migrations[0].Name != "create_people_table" {
+		t.Fatalf("unexpected migrations %+v", m.migrations)
+	}
+	for i, mig := range m.migrations {
+		if mig.Down == "" {
+			t.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+		}
+		if i > 0 && m.previous(mig.Version) != m.migrations[i-1].Version {
+			t.Errorf("wrong previous version for %d", mig.Version)
+		}
+	}
+	if m.previous(m.migrations[0].Version) != 0 {
+		t.Error("first migration must roll back to version 0")
+	}
+}
+
+// Существующие ключи не должны получать admin при миграции
+func TestScopesBackfillOmitsAdmin(t *testing.T) {
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mig := range m.migrations {
+		if mig.Name == "add_api_keys_scopes" {
+			if strings.Contains(mig.Up, "admin}") || strings.Contains(mig.Up, ",admin") {
+				t.Errorf("scopes backfill grants admin:\n%s", mig.Up)
+			}
+			return
+		}
+	}
+	t.Fatal("add_api_keys_scopes migration not found")
+}
+
+func TestLoadMigrations_Errors(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing up":     {"1_init.down.sql": {Data: []byte("DROP TABLE t;")}},
+		"bad name":       {"init.up.sql": {Data: []byte("CREATE TABLE t ();")}},
+		"bad suffix":     {"1_init.sql": {Data: []byte("CREATE TABLE t ();")}},
+		"name conflicts": {"1_a.up.sql": {Data: []byte("SELECT 1;")}, "1_b.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for name, fsys := range cases {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS enrichment_cache (
+    name TEXT PRIMARY KEY,
+    person JSONB NOT NULL,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_cache_expires_at ON enrichment_cache(expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_deleted_at;
+
+ALTER TABLE people DROP COLUMN IF EXISTS deleted_at;
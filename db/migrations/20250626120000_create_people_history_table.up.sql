@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS people_history (
+    id BIGSERIAL PRIMARY KEY,
+    person_id INT NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    action TEXT NOT NULL
+        CHECK (action IN ('create', 'update', 'patch', 'delete', 'restore', 'revert', 'enrich')),
+    before JSONB,
+    after JSONB,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    request_id TEXT,
+    user_id TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_history_person_id ON people_history(person_id, id DESC);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trigger_increment_version ON people;
+DROP FUNCTION IF EXISTS increment_version();
+
+ALTER TABLE people DROP COLUMN IF EXISTS version;
@@ -0,0 +1,5 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+
+-- Ключи, выпущенные до появления прав, сохраняют доступ к людям;
+-- admin выдаётся только явно, новым ключом с ролью или правом admin
+UPDATE api_keys SET scopes = '{people:read,people:write,people:delete}';
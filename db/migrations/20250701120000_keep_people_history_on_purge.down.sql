@@ -0,0 +1,9 @@
+-- Внешний ключ нельзя вернуть, пока есть история окончательно удалённых людей
+DELETE FROM people_history WHERE person_id NOT IN (SELECT id FROM people);
+
+ALTER TABLE people_history DROP CONSTRAINT IF EXISTS people_history_action_check;
+ALTER TABLE people_history ADD CONSTRAINT people_history_action_check
+    CHECK (action IN ('create', 'update', 'patch', 'delete', 'restore', 'revert', 'enrich'));
+
+ALTER TABLE people_history ADD CONSTRAINT people_history_person_id_fkey
+    FOREIGN KEY (person_id) REFERENCES people(id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+-- История — журнал аудита: она не должна исчезать при окончательном удалении человека
+ALTER TABLE people_history DROP CONSTRAINT IF EXISTS people_history_person_id_fkey;
+
+ALTER TABLE people_history DROP CONSTRAINT IF EXISTS people_history_action_check;
+ALTER TABLE people_history ADD CONSTRAINT people_history_action_check
+    CHECK (action IN ('create', 'update', 'patch', 'delete', 'restore', 'revert', 'enrich', 'purge'));
@@ -0,0 +1,2 @@
+-- Атрибуты от провайдеров обогащения, объявленных в конфигурации (ethnicity, locale, ...)
+ALTER TABLE people ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
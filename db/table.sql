@@ -16,13 +16,19 @@ CREATE TABLE IF NOT EXISTS people (
     age INT CHECK (age > 0 AND age < 120),
     nationality CHAR(2),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    enrichment_status TEXT NOT NULL DEFAULT 'completed'
+        CHECK (enrichment_status IN ('pending', 'completed', 'failed')),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    version INT NOT NULL DEFAULT 1,
+    attributes JSONB NOT NULL DEFAULT '{}'
 );
 
 
 CREATE INDEX IF NOT EXISTS idx_people_name ON people(name);
 CREATE INDEX IF NOT EXISTS idx_people_surname ON people(surname);
 CREATE INDEX IF NOT EXISTS idx_people_nationality ON people(nationality);
+CREATE INDEX IF NOT EXISTS idx_people_deleted_at ON people(deleted_at) WHERE deleted_at IS NOT NULL;
 
 
 CREATE OR REPLACE FUNCTION update_updated_at()
@@ -36,3 +42,86 @@ $$ LANGUAGE plpgsql;
 CREATE TRIGGER trigger_update_updated_at
 BEFORE UPDATE ON people
 FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+
+CREATE OR REPLACE FUNCTION increment_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_increment_version
+BEFORE UPDATE ON people
+FOR EACH ROW EXECUTE FUNCTION increment_version();
+
+
+CREATE TABLE IF NOT EXISTS enrichment_cache (
+    name TEXT PRIMARY KEY,
+    person JSONB NOT NULL,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_cache_expires_at ON enrichment_cache(expires_at);
+
+
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    person_id INT NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    last_error TEXT,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_queued ON enrichment_jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_person_id ON enrichment_jobs(person_id);
+
+
+CREATE TABLE IF NOT EXISTS people_history (
+    id BIGSERIAL PRIMARY KEY,
+    -- Без внешнего ключа: история остаётся после окончательного удаления человека
+    person_id INT NOT NULL,
+    action TEXT NOT NULL
+        CHECK (action IN ('create', 'update', 'patch', 'delete', 'restore', 'revert', 'enrich', 'purge')),
+    before JSONB,
+    after JSONB,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    request_id TEXT,
+    user_id TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_history_person_id ON people_history(person_id, id DESC);
+
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    key_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_updated_at ON rate_limits(updated_at);
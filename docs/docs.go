@@ -20,7 +20,7 @@ const docTemplate = `{
     "paths": {
         "/people": {
             "get": {
-                "description": "Возвращает список людей с возможностью фильтрации по имени",
+                "description": "Возвращает страницу людей с фильтрацией, сортировкой и пагинацией (limit/offset или курсор)",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,27 +38,72 @@ const docTemplate = `{
                         "name": "name",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Фамилия для фильтрации",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Пол",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальный возраст",
+                        "name": "age_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный возраст",
+                        "name": "age_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код страны",
+                        "name": "nationality",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Ограничение количества",
+                        "description": "Ограничение количества (по умолчанию 20, максимум 1000)",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Смещение",
+                        "description": "Смещение (нельзя совмещать с cursor)",
                         "name": "offset",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor/prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: id, name, surname, age, created_at, updated_at; префикс '-' для убывания (по умолчанию -created_at)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Person"
-                            }
+                            "$ref": "#/definitions/models.PeoplePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -229,6 +274,53 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID идентификатор запроса (X-Request-ID) для поиска в логах",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeoplePage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Person"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "sort": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Person": {
             "type": "object",
             "properties": {
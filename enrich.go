@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/models"
+)
+
+// runEnrichCommand обогащает имя через настроенных провайдеров и печатает результат,
+// ничего не сохраняя; удобно для проверки провайдеров и их политик.
+// При частичной ошибке печатается то, что удалось получить, код завершения 1.
+func runEnrichCommand(cfg *config.Config, args []string) int {
+	if len(args) != 1 || args[0] == "" {
+		fmt.Fprintln(os.Stderr, "usage: go-people-api enrich <name>")
+		return 2
+	}
+
+	service, err := setupEnrichment(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "enrich:", err)
+		return 1
+	}
+	defer service.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	person, err := service.Enrich(ctx, args[0])
+	if person == nil {
+		person = &models.Person{}
+	}
+	result := struct {
+		Name        string `json:"name"`
+		Age         int    `json:"age,omitempty"`
+		Gender      string `json:"gender,omitempty"`
+		Nationality string `json:"nationality,omitempty"`
+	}{args[0], person.Age, person.Gender, person.Nationality}
+	if code := printJSON(result); code != 0 {
+		return code
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "enrich:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"go-people-api/log"
+	"go-people-api/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnrichmentCache interface {
+	Stats() models.CacheStats
+	Purge(ctx context.Context, name string) error
+}
+
+type EnrichmentStatus interface {
+	ProviderStatuses() []models.ProviderStatus
+	ProviderQuotas() []models.QuotaStatus
+}
+
+var (
+	enrichmentCache  EnrichmentCache
+	enrichmentStatus EnrichmentStatus
+)
+
+func SetEnrichmentCache(cache EnrichmentCache) {
+	enrichmentCache = cache
+}
+
+func SetEnrichmentStatus(status EnrichmentStatus) {
+	enrichmentStatus = status
+}
+
+// GetEnrichmentProviders возвращает состояние провайдеров и их автоматов
+func GetEnrichmentProviders(c *gin.Context) {
+	if enrichmentStatus == nil {
+		c.JSON(http.StatusOK, []models.ProviderStatus{})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrichmentStatus.ProviderStatuses())
+}
+
+// GetEnrichmentQuotas возвращает квоты провайдеров по заголовкам их последних ответов
+func GetEnrichmentQuotas(c *gin.Context) {
+	if enrichmentStatus == nil {
+		c.JSON(http.StatusOK, []models.QuotaStatus{})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrichmentStatus.ProviderQuotas())
+}
+
+func GetEnrichmentCacheStats(c *gin.Context) {
+	if enrichmentCache == nil {
+		respondError(c, http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Enrichment cache is disabled",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrichmentCache.Stats())
+}
+
+// PurgeEnrichmentCache удаляет запись для имени (:name) или весь кэш
+func PurgeEnrichmentCache(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	if enrichmentCache == nil {
+		respondError(c, http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Enrichment cache is disabled",
+		})
+		return
+	}
+
+	name := c.Param("name")
+	if err := enrichmentCache.Purge(ctx, name); err != nil {
+		log.WithContext(ctx).WithError(err).Error("Failed to purge enrichment cache")
+		respondError(c, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "cache_error",
+			Message: "Failed to purge enrichment cache",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"name":   name,
+	})
+}
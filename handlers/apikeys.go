@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-people-api/auth"
+	"go-people-api/log"
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyManager выпуск и отзыв ключей API
+type APIKeyManager interface {
+	Issue(ctx context.Context, userID, name string, scopes []string, ttl time.Duration) (*models.IssuedAPIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+var apiKeyManager APIKeyManager
+
+func SetAPIKeyManager(manager APIKeyManager) {
+	apiKeyManager = manager
+}
+
+// IssueAPIKey выпускает ключ; сам ключ виден только в этом ответе
+func IssueAPIKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	var input models.IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid input data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var ttl time.Duration
+	if input.ExpiresIn != "" {
+		d, err := time.ParseDuration(input.ExpiresIn)
+		if err != nil || d <= 0 {
+			respondError(c, http.StatusBadRequest, models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "expires_in must be a positive duration, e.g. 720h",
+			})
+			return
+		}
+		ttl = d
+	}
+
+	if len(input.Roles) == 0 && len(input.Scopes) == 0 {
+		input.Roles = []string{"reader"}
+	}
+	scopes, err := auth.ResolveScopes(input.Roles, input.Scopes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid roles or scopes",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	issued, err := apiKeyManager.Issue(ctx, input.UserID, input.Name, scopes, ttl)
+	if err != nil {
+		handleDatabaseError(c, ctx, err, "Failed to issue api key")
+		return
+	}
+
+	log.WithContext(ctx).Infof("API key %s issued for %s with scopes %v", issued.Prefix, issued.UserID, issued.Scopes)
+	c.JSON(http.StatusCreated, issued)
+}
+
+func ListAPIKeys(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	keys, err := apiKeyManager.List(ctx)
+	if err != nil {
+		handleDatabaseError(c, ctx, err, "Failed to list api keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "API key ID must be an integer",
+		})
+		return
+	}
+
+	if err := apiKeyManager.Revoke(ctx, id); err != nil {
+		if errors.Is(err, auth.ErrKeyNotFound) {
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "API key not found or already revoked",
+			})
+			return
+		}
+		handleDatabaseError(c, ctx, err, "Failed to revoke api key")
+		return
+	}
+
+	log.WithContext(ctx).Infof("API key %d revoked", id)
+	c.JSON(http.StatusOK, gin.H{
+		"status": "revoked",
+		"id":     id,
+	})
+}
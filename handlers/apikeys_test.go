@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-people-api/auth"
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyManagement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := auth.NewAPIKeys(auth.NewMemoryKeyStore())
+	SetAPIKeyManager(keys)
+
+	r := gin.New()
+	r.POST("/api/v1/admin/apikeys", IssueAPIKey)
+	r.GET("/api/v1/admin/apikeys", ListAPIKeys)
+	r.DELETE("/api/v1/admin/apikeys/:id", RevokeAPIKey)
+
+	if w := doRequest(r, http.MethodPost, "/api/v1/admin/apikeys", map[string]string{"name": "ci"}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing user_id: expected 400, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodPost, "/api/v1/admin/apikeys", map[string]string{"user_id": "alice", "expires_in": "soon"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid expires_in: expected 400, got %d", w.Code)
+	}
+
+	if w := doRequest(r, http.MethodPost, "/api/v1/admin/apikeys", map[string]interface{}{"user_id": "alice", "roles": []string{"root"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown role: expected 400, got %d", w.Code)
+	}
+
+	w := doRequest(r, http.MethodPost, "/api/v1/admin/apikeys", map[string]interface{}{
+		"user_id": "alice", "name": "ci", "expires_in": "720h", "roles": []string{"operator"},
+	})
+	var issued models.IssuedAPIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &issued); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if !auth.IsAPIKey(issued.Key) || issued.UserID != "alice" || issued.ExpiresAt == nil ||
+		fmt.Sprint(issued.Scopes) != "[people:read people:write]" {
+		t.Fatalf("unexpected issued key: %+v", issued)
+	}
+
+	var list []map[string]interface{}
+	_ = json.Unmarshal(doRequest(r, http.MethodGet, "/api/v1/admin/apikeys", nil).Body.Bytes(), &list)
+	if len(list) != 1 || list[0]["prefix"] != issued.Prefix {
+		t.Fatalf("unexpected list: %v", list)
+	}
+	if _, ok := list[0]["key"]; ok {
+		t.Error("list must not expose the key")
+	}
+
+	if w := doRequest(r, http.MethodDelete, "/api/v1/admin/apikeys/1", nil); w.Code != http.StatusOK {
+		t.Errorf("revoke: expected 200, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodDelete, "/api/v1/admin/apikeys/1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("second revoke: expected 404, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodDelete, "/api/v1/admin/apikeys/abc", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-people-api/log"
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxBatchSize максимальное число людей в одном пакетном запросе
+	maxBatchSize = 1000
+	// maxBatchBodySize ограничивает тело пакетного запроса: поля человека не длиннее
+	// 100 символов, 4 КиБ на элемент хватает даже с экранированием \uXXXX
+	maxBatchBodySize = maxBatchSize * 4 << 10
+)
+
+// BatchPersonService сервис, умеющий обогащать несколько имён за раз
+type BatchPersonService interface {
+	EnrichBatch(ctx context.Context, names []string) (map[string]*models.Person, map[string]error)
+}
+
+// CreatePeopleBatch создаёт людей пакетом. Каждое уникальное имя обогащается один раз,
+// записи сохраняются одной транзакцией, результат и ошибка возвращаются для каждого элемента.
+func CreatePeopleBatch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	// Валидация выполняется поэлементно, чтобы ошибка одной записи не отклоняла весь пакет
+	// Тело ограничивается до разбора, иначе огромный массив целиком окажется в памяти
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodySize)
+	var input []models.Person
+	if err := json.NewDecoder(c.Request.Body).Decode(&input); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, models.ErrorResponse{
+				Error:   "payload_too_large",
+				Message: "Batch request body is too large",
+				Details: fmt.Sprintf("limit is %d bytes", tooLarge.Limit),
+			})
+			return
+		}
+		log.WithContext(ctx).WithError(err).Warn("Invalid batch input")
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid input data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if len(input) == 0 || len(input) > maxBatchSize {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Batch must contain from 1 to 1000 people",
+		})
+		return
+	}
+
+	response := models.BatchResponse{Results: make([]models.BatchItemResult, len(input))}
+	var (
+		valid   []int
+		names   []string
+		pending []*models.Person
+	)
+	for i := range input {
+		response.Results[i].Index = i
+		if errResp := validateBatchItem(&input[i]); errResp != nil {
+			response.Results[i].Status = "failed"
+			response.Results[i].Error = errResp
+			continue
+		}
+		valid = append(valid, i)
+		names = append(names, input[i].Name)
+	}
+
+	async := useAsyncEnrichment(c)
+	var (
+		enriched map[string]*models.Person
+		errs     map[string]error
+	)
+	if !async && len(names) > 0 {
+		enriched, errs = enrichNames(ctx, names)
+	}
+
+	for _, i := range valid {
+		person := &input[i]
+		if async {
+			person.EnrichmentStatus = models.EnrichmentPending
+		} else {
+			person = models.MergeEnrichment(person, enriched[person.Name])
+			person.EnrichmentStatus = models.EnrichmentCompleted
+			if errs[person.Name] != nil {
+				person.EnrichmentStatus = models.EnrichmentFailed
+			}
+		}
+		pending = append(pending, person)
+	}
+
+	if len(pending) > 0 {
+		create := personRepository.CreateBatch
+		if async {
+			create = enrichmentQueue.CreateAndEnqueue
+		}
+		createErrs, err := create(ctx, pending)
+		if err != nil {
+			handleDatabaseError(c, ctx, err, "Failed to create people records")
+			return
+		}
+
+		for n, i := range valid {
+			if createErrs[n] != nil {
+				log.WithContext(ctx).WithError(createErrs[n]).Warn("Failed to create batch item")
+				response.Results[i].Status = "failed"
+				response.Results[i].Error = batchDatabaseError(createErrs[n])
+				continue
+			}
+
+			response.Results[i].Status = "created"
+			response.Results[i].Person = pending[n]
+		}
+	}
+
+	for _, result := range response.Results {
+		if result.Status == "created" {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+
+	status := http.StatusCreated
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
+func validateBatchItem(person *models.Person) *models.ErrorResponse {
+	if err := binding.Validator.ValidateStruct(person); err != nil {
+		return &models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid input data",
+			Details: err.Error(),
+		}
+	}
+	if person.Name == "" || person.Surname == "" {
+		return &models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Name and surname are required",
+		}
+	}
+	return nil
+}
+
+// enrichNames обогащает имена пакетом, если сервис это поддерживает, иначе по одному
+func enrichNames(ctx context.Context, names []string) (map[string]*models.Person, map[string]error) {
+	if batch, ok := personService.(BatchPersonService); ok {
+		return batch.EnrichBatch(ctx, names)
+	}
+
+	enriched := make(map[string]*models.Person)
+	errs := make(map[string]error)
+	for _, name := range names {
+		if _, done := enriched[name]; done {
+			continue
+		}
+		person, err := personService.Enrich(ctx, name)
+		enriched[name] = person
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Partial enrichment failure")
+			errs[name] = err
+		}
+	}
+	return enriched, errs
+}
+
+func batchDatabaseError(err error) *models.ErrorResponse {
+	resp := &models.ErrorResponse{
+		Error:   "database_error",
+		Message: "Failed to create person record",
+	}
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		resp.Details = pgErr.Message
+	}
+	return resp
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-people-api/models"
+)
+
+type stubBatchService struct {
+	stubPersonService
+	names []string
+}
+
+func (s *stubBatchService) EnrichBatch(_ context.Context, names []string) (map[string]*models.Person, map[string]error) {
+	s.names = names
+	people := make(map[string]*models.Person)
+	for _, name := range names {
+		people[name] = &models.Person{Age: len(name), Gender: "female"}
+	}
+	return people, nil
+}
+
+func TestCreatePeopleBatch(t *testing.T) {
+	service := &stubBatchService{}
+	r, repo := setupTestRouter(t, service)
+	r.POST("/api/v1/people/batch", CreatePeopleBatch)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/people/batch", []map[string]interface{}{
+		{"name": "Anna", "surname": "Smirnova"},
+		{"name": "Anna", "surname": "Ivanova", "gender": "male"},
+		{"name": "Olga"},
+		{"name": "Maria", "surname": "Petrova", "age": 500},
+	})
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp models.BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Created != 2 || resp.Failed != 2 || len(resp.Results) != 4 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Results[1].Person.Gender != "male" || resp.Results[1].Person.Age != 4 {
+		t.Errorf("unexpected merged person: %+v", resp.Results[1].Person)
+	}
+	if resp.Results[2].Error == nil || resp.Results[3].Error == nil || resp.Results[3].Index != 3 {
+		t.Errorf("expected per-item errors: %+v", resp.Results)
+	}
+	if len(service.names) != 2 {
+		t.Errorf("expected only valid names to be enriched, got %v", service.names)
+	}
+
+	page, _ := repo.List(context.Background(), models.PersonFilter{})
+	if page.Total != 2 {
+		t.Errorf("expected 2 stored people, got %d", page.Total)
+	}
+}
+
+func TestCreatePeopleBatch_TooLarge(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{})
+	r.POST("/api/v1/people/batch", CreatePeopleBatch)
+
+	batch := make([]models.Person, maxBatchSize+1)
+	if w := doRequest(r, http.MethodPost, "/api/v1/people/batch", batch); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodPost, "/api/v1/people/batch", []models.Person{}); w.Code != http.StatusBadRequest {
+		t.Errorf("empty batch: expected 400, got %d", w.Code)
+	}
+}
+
+func TestCreatePeopleBatch_BodyTooLarge(t *testing.T) {
+	r, repo := setupTestRouter(t, &stubBatchService{})
+	r.POST("/api/v1/people/batch", CreatePeopleBatch)
+
+	long := strings.Repeat("a", maxBatchBodySize)
+	w := doRequest(r, http.MethodPost, "/api/v1/people/batch", []map[string]string{{"name": long, "surname": "Ivanova"}})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+	if page, _ := repo.List(context.Background(), models.PersonFilter{}); page.Total != 0 {
+		t.Errorf("expected nothing stored, got %d", page.Total)
+	}
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"go-people-api/models"
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError отвечает ошибкой, добавляя идентификатор запроса для поиска в логах
+func respondError(c *gin.Context, status int, resp models.ErrorResponse) {
+	resp.RequestID = requestid.FromContext(c.Request.Context())
+	c.JSON(status, resp)
+}
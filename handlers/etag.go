@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-people-api/models"
+	"go-people-api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// personETag строгий ETag человека по его версии
+func personETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func setPersonETag(c *gin.Context, person *models.Person) {
+	if person != nil && person.Version > 0 {
+		c.Header("ETag", personETag(person.Version))
+	}
+}
+
+// etagMatches проверяет, есть ли ETag в списке из If-Match / If-None-Match.
+// weak разрешает слабое сравнение (W/"..."), как требуется для If-None-Match.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion возвращает версию из If-Match, которую должен иметь человек для изменения.
+// 0 означает, что заголовка нет (или передан "*") и изменение безусловное.
+// При нескольких ETag текущая версия читается из базы и сверяется со списком;
+// если условие заведомо не выполняется, ответ 412 уже отправлен и ok == false.
+func ifMatchVersion(c *gin.Context, ctx context.Context, id int) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	if v, err := strconv.Atoi(strings.Trim(header, `"`)); err == nil && header == personETag(v) {
+		return v, true
+	}
+
+	person, err := personRepository.Get(ctx, id, false)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			respondPreconditionFailed(c)
+			return 0, false
+		}
+		handleDatabaseError(c, ctx, err, "Failed to fetch person")
+		return 0, false
+	}
+	if !etagMatches(header, personETag(person.Version), false) {
+		respondPreconditionFailed(c)
+		return 0, false
+	}
+	return person.Version, true
+}
+
+func respondPreconditionFailed(c *gin.Context) {
+	respondError(c, http.StatusPreconditionFailed, models.ErrorResponse{
+		Error:   "precondition_failed",
+		Message: "Person was modified by another request, fetch it again and retry",
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func doRequestWithHeader(r *gin.Engine, method, path, body, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(header, value)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPersonETags(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	_ = repo.Create(context.Background(), &models.Person{Name: "Ivan", Surname: "Petrov"})
+	path := "/api/v1/people/1"
+
+	w := doRequest(r, http.MethodGet, path, nil)
+	etag := w.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("expected ETag \"1\", got %q", etag)
+	}
+
+	if w = doRequestWithHeader(r, http.MethodGet, path, "", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: expected 304, got %d", w.Code)
+	}
+	if w = doRequestWithHeader(r, http.MethodGet, path, "", "If-None-Match", `W/"1"`); w.Code != http.StatusNotModified {
+		t.Errorf("weak If-None-Match: expected 304, got %d", w.Code)
+	}
+
+	w = doRequestWithHeader(r, http.MethodPatch, path, `{"age": 30}`, "If-Match", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PATCH with current ETag: expected 200 and ETag \"2\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// Второй оператор всё ещё держит старую версию
+	if w = doRequestWithHeader(r, http.MethodPut, path, `{"name": "Petr", "surname": "Ivanov"}`, "If-Match", etag); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with stale ETag: expected 412, got %d", w.Code)
+	}
+	if w = doRequestWithHeader(r, http.MethodDelete, path, "", "If-Match", `"5", "7"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with stale ETags: expected 412, got %d", w.Code)
+	}
+	if w = doRequestWithHeader(r, http.MethodGet, path, "", "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("If-None-Match with stale ETag: expected 200, got %d", w.Code)
+	}
+
+	got, _ := repo.Get(context.Background(), 1, false)
+	if got.Name != "Ivan" || got.Age != 30 || got.Version != 2 {
+		t.Errorf("unexpected person: %+v", got)
+	}
+
+	if w = doRequestWithHeader(r, http.MethodDelete, path, "", "If-Match", `"1", "2"`); w.Code != http.StatusOK {
+		t.Errorf("DELETE with matching ETag list: expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPersonHistory возвращает историю изменений человека, включая удалённых
+// и окончательно удалённых (последняя запись у них — purge)
+func GetPersonHistory(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Person ID must be an integer",
+		})
+		return
+	}
+
+	var filter models.HistoryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid pagination parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, err := personRepository.History(ctx, id, filter)
+	if err != nil {
+		handleDatabaseError(c, ctx, err, "Failed to fetch person history")
+		return
+	}
+
+	// Пустая история у существующего человека — 200, у несуществующего — 404
+	if page.Total == 0 {
+		if _, err := personRepository.Get(ctx, id, true); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				respondError(c, http.StatusNotFound, models.ErrorResponse{
+					Error:   "not_found",
+					Message: "Person not found",
+				})
+				return
+			}
+			handleDatabaseError(c, ctx, err, "Failed to fetch person")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RevertPerson возвращает данные человека к состоянию после указанной записи истории.
+// Сам возврат тоже записывается в историю, поэтому его можно отменить.
+func RevertPerson(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Person ID must be an integer",
+		})
+		return
+	}
+
+	var input models.RevertRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid input data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	person, err := personRepository.Revert(ctx, id, input.HistoryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Person not found",
+			})
+		case errors.Is(err, repository.ErrHistoryNotFound):
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
+				Error:   "history_not_found",
+				Message: "History entry not found for this person",
+			})
+		default:
+			handleDatabaseError(c, ctx, err, "Failed to revert person")
+		}
+		return
+	}
+
+	log.WithContext(ctx).Infof("Person %d reverted to history entry %d", id, input.HistoryID)
+	setPersonETag(c, person)
+	c.JSON(http.StatusOK, person)
+}
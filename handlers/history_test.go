@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+	"go-people-api/requestid"
+)
+
+func TestPersonHistoryAndRevert(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	r.GET("/api/v1/people/:id/history", GetPersonHistory)
+	r.POST("/api/v1/people/:id/revert", RevertPerson)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	_ = repo.Create(ctx, &models.Person{Name: "Ivan", Surname: "Petrov", Age: 20})
+
+	doRequest(r, http.MethodPatch, "/api/v1/people/1", map[string]interface{}{"age": 33})
+	doRequest(r, http.MethodPatch, "/api/v1/people/1", map[string]interface{}{"age": 33})
+	doRequest(r, http.MethodPut, "/api/v1/people/1", map[string]interface{}{"name": "Petr", "surname": "Ivanov"})
+
+	var page models.HistoryPage
+	w := doRequest(r, http.MethodGet, "/api/v1/people/1/history", nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected history, got %d: %s", w.Code, w.Body.String())
+	}
+	// Повторный PATCH без изменений в историю не попадает
+	if page.Total != 3 || len(page.Data) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", page)
+	}
+	latest, patch, create := page.Data[0], page.Data[1], page.Data[2]
+	if create.Action != models.HistoryCreate || create.Before != nil || create.RequestID != "req-1" {
+		t.Errorf("unexpected create entry: %+v", create)
+	}
+	if patch.Action != models.HistoryPatch || fmt.Sprint(patch.ChangedFields) != "[age]" ||
+		patch.Before.Age != 20 || patch.After.Age != 33 {
+		t.Errorf("unexpected patch entry: %+v", patch)
+	}
+	if latest.Action != models.HistoryUpdate || fmt.Sprint(latest.ChangedFields) != "[name surname age]" {
+		t.Errorf("unexpected update entry: %+v", latest)
+	}
+
+	w = doRequest(r, http.MethodPost, "/api/v1/people/1/revert", map[string]interface{}{"history_id": patch.ID})
+	var person models.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &person)
+	if w.Code != http.StatusOK || person.Name != "Ivan" || person.Surname != "Petrov" || person.Age != 33 {
+		t.Fatalf("unexpected revert result %d: %s", w.Code, w.Body.String())
+	}
+
+	page = models.HistoryPage{}
+	_ = json.Unmarshal(doRequest(r, http.MethodGet, "/api/v1/people/1/history?limit=1", nil).Body.Bytes(), &page)
+	if page.Total != 4 || len(page.Data) != 1 || page.Data[0].Action != models.HistoryRevert {
+		t.Errorf("expected revert to be recorded, got %+v", page)
+	}
+
+	if w := doRequest(r, http.MethodPost, "/api/v1/people/1/revert", map[string]interface{}{"history_id": 999}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown history entry: expected 404, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodGet, "/api/v1/people/42/history", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown person: expected 404, got %d", w.Code)
+	}
+}
+
+func TestPersonHistory_SurvivesPurge(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	r.GET("/api/v1/people/:id/history", GetPersonHistory)
+
+	ctx := context.Background()
+	_ = repo.Create(ctx, &models.Person{Name: "Ivan", Surname: "Petrov"})
+	doRequest(r, http.MethodDelete, "/api/v1/people/1", nil)
+	if n, err := repo.Purge(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected 1 purged person, got %d, %v", n, err)
+	}
+
+	var page models.HistoryPage
+	w := doRequest(r, http.MethodGet, "/api/v1/people/1/history", nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected history after purge, got %d: %s", w.Code, w.Body.String())
+	}
+	if page.Total != 3 || page.Data[0].Action != models.HistoryPurge || page.Data[0].After != nil ||
+		page.Data[0].Before == nil || page.Data[2].Action != models.HistoryCreate {
+		t.Errorf("unexpected history after purge: %+v", page)
+	}
+}
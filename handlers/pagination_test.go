@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-people-api/models"
+)
+
+func TestGetPeople_InvalidPagination(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{})
+
+	cases := map[string]string{
+		"limit=0x":      "validation_error",
+		"limit=1001":    "validation_error",
+		"limit=-1":      "validation_error",
+		"offset=-5":     "validation_error",
+		"sort=password": "validation_error",
+		"cursor=broken": "invalid_cursor",
+	}
+	for query, code := range cases {
+		w := doRequest(r, http.MethodGet, "/api/v1/people?"+query, nil)
+
+		var resp models.ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Error != code {
+			t.Errorf("%s: expected 400 %s, got %d %s", query, code, w.Code, resp.Error)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// patchableFields поля, которые можно менять через PATCH
+var patchableFields = []string{"name", "surname", "patronymic", "gender", "age", "nationality"}
+
+// patchError ошибка разбора тела PATCH с HTTP-статусом ответа
+type patchError struct {
+	status int
+	resp   models.ErrorResponse
+}
+
+func invalidPatch(message string, err error) *patchError {
+	resp := models.ErrorResponse{Error: "validation_error", Message: message}
+	if err != nil {
+		resp.Details = err.Error()
+	}
+	return &patchError{status: http.StatusBadRequest, resp: resp}
+}
+
+// jsonPatchOp операция RFC 6902; HasValue отличает "value": null от отсутствия value
+type jsonPatchOp struct {
+	Op       string
+	Path     string
+	Value    json.RawMessage
+	HasValue bool
+}
+
+func (op *jsonPatchOp) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	// Для "value": null RawMessage содержит литерал null, для отсутствующего поля остаётся nil
+	*op = jsonPatchOp{Op: raw.Op, Path: raw.Path, Value: raw.Value, HasValue: raw.Value != nil}
+	return nil
+}
+
+// parsePatch разбирает тело PATCH по Content-Type:
+// application/json — models.UpdatePersonRequest (null игнорируется),
+// application/merge-patch+json — RFC 7396 (null очищает поле),
+// application/json-patch+json — RFC 6902 с операциями test, remove и replace.
+func parsePatch(c *gin.Context) (models.UpdatePersonRequest, *patchError) {
+	var input models.UpdatePersonRequest
+
+	switch c.ContentType() {
+	case "", "application/json":
+		if err := c.ShouldBindJSON(&input); err != nil {
+			return input, invalidPatch("Invalid input data", err)
+		}
+		return input, nil
+	case mergePatchContentType, jsonPatchContentType:
+	default:
+		return input, &patchError{
+			status: http.StatusUnsupportedMediaType,
+			resp: models.ErrorResponse{
+				Error:   "unsupported_media_type",
+				Message: "PATCH accepts application/json, " + mergePatchContentType + " or " + jsonPatchContentType,
+			},
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return input, invalidPatch("Failed to read request body", err)
+	}
+
+	var (
+		changes map[string]json.RawMessage
+		tests   []models.FieldTest
+		perr    *patchError
+	)
+	if c.ContentType() == mergePatchContentType {
+		changes, perr = parseMergePatch(body)
+	} else {
+		changes, tests, perr = parseJSONPatch(body)
+	}
+	if perr != nil {
+		return input, perr
+	}
+
+	input, perr = buildUpdateRequest(changes)
+	if perr != nil {
+		return input, perr
+	}
+	input.Tests = tests
+
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return input, invalidPatch("Invalid input data", err)
+	}
+	return input, nil
+}
+
+func parseMergePatch(body []byte) (map[string]json.RawMessage, *patchError) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(body, &changes); err != nil {
+		return nil, invalidPatch("Merge patch must be a JSON object", err)
+	}
+	for field := range changes {
+		if !slices.Contains(patchableFields, field) {
+			return nil, invalidPatch("Invalid input data", fmt.Errorf("unknown field %q", field))
+		}
+	}
+	return changes, nil
+}
+
+// parseJSONPatch применяет операции по порядку к набору изменений.
+// test для поля, уже изменённого в этом же патче, проверяется сразу по новому значению,
+// остальные test проверяются в базе в момент обновления.
+func parseJSONPatch(body []byte) (map[string]json.RawMessage, []models.FieldTest, *patchError) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, nil, invalidPatch("JSON patch must be an array of operations", err)
+	}
+
+	changes := make(map[string]json.RawMessage)
+	var tests []models.FieldTest
+	for i, op := range ops {
+		field, err := patchPath(op.Path)
+		if err != nil {
+			return nil, nil, invalidPatch(fmt.Sprintf("Invalid operation %d", i), err)
+		}
+
+		switch op.Op {
+		case "replace", "test":
+			if !op.HasValue {
+				return nil, nil, invalidPatch(fmt.Sprintf("Invalid operation %d", i), errors.New("value is required"))
+			}
+		case "remove":
+		default:
+			return nil, nil, invalidPatch(fmt.Sprintf("Invalid operation %d", i),
+				fmt.Errorf("unsupported op %q: expected test, remove or replace", op.Op))
+		}
+
+		switch op.Op {
+		case "replace":
+			changes[field] = op.Value
+		case "remove":
+			changes[field] = json.RawMessage("null")
+		case "test":
+			if pending, ok := changes[field]; ok {
+				if !jsonEqual(pending, op.Value) {
+					return nil, nil, &patchError{status: http.StatusConflict, resp: testFailed(i)}
+				}
+				continue
+			}
+			value, err := decodeFieldValue(field, op.Value)
+			if err != nil {
+				return nil, nil, invalidPatch(fmt.Sprintf("Invalid operation %d", i), err)
+			}
+			tests = append(tests, models.FieldTest{Field: field, Value: value})
+		}
+	}
+	return changes, tests, nil
+}
+
+func testFailed(index int) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error:   "test_failed",
+		Message: "JSON patch test operation failed",
+		Details: fmt.Sprintf("operation %d", index),
+	}
+}
+
+// patchPath переводит JSON Pointer вида "/age" в имя поля
+func patchPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid path %q", path)
+	}
+	field := strings.NewReplacer("~1", "/", "~0", "~").Replace(path[1:])
+	if !slices.Contains(patchableFields, field) {
+		return "", fmt.Errorf("unknown path %q", path)
+	}
+	return field, nil
+}
+
+// buildUpdateRequest собирает запрос на изменение; null очищает поле
+func buildUpdateRequest(changes map[string]json.RawMessage) (models.UpdatePersonRequest, *patchError) {
+	var input models.UpdatePersonRequest
+	for _, field := range patchableFields {
+		raw, ok := changes[field]
+		if !ok {
+			continue
+		}
+		if isJSONNull(raw) {
+			if !slices.Contains(models.NullableFields, field) {
+				return input, invalidPatch("Invalid input data", fmt.Errorf("field %q cannot be null", field))
+			}
+			input.Null = append(input.Null, field)
+			continue
+		}
+
+		var target interface{}
+		switch field {
+		case "name":
+			target = &input.Name
+		case "surname":
+			target = &input.Surname
+		case "patronymic":
+			target = &input.Patronymic
+		case "gender":
+			target = &input.Gender
+		case "age":
+			target = &input.Age
+		case "nationality":
+			target = &input.Nationality
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return input, invalidPatch("Invalid input data", fmt.Errorf("field %q: %w", field, err))
+		}
+	}
+	return input, nil
+}
+
+// decodeFieldValue разбирает значение для test: nil для null, int для age, строку для остальных полей
+func decodeFieldValue(field string, raw json.RawMessage) (interface{}, error) {
+	if isJSONNull(raw) {
+		return nil, nil
+	}
+	if field == "age" {
+		var age int
+		if err := json.Unmarshal(raw, &age); err != nil {
+			return nil, fmt.Errorf("field %q: %w", field, err)
+		}
+		return age, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("field %q: %w", field, err)
+	}
+	return s, nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return fmt.Sprint(va) == fmt.Sprint(vb)
+}
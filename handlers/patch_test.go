@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-people-api/models"
+)
+
+func TestPatchPerson_MergePatch(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	_ = repo.Create(context.Background(), &models.Person{
+		Name: "Ivan", Surname: "Petrov", Patronymic: "Sergeevich", Gender: "male", Age: 30, Nationality: "RU",
+	})
+	path := "/api/v1/people/1"
+
+	w := doRequestWithHeader(r, http.MethodPatch, path, `{"patronymic": null, "nationality": null, "age": 31}`,
+		"Content-Type", mergePatchContentType)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ := repo.Get(context.Background(), 1, false)
+	if got.Patronymic != "" || got.Nationality != "" || got.Age != 31 || got.Gender != "male" {
+		t.Errorf("unexpected person: %+v", got)
+	}
+
+	for body, code := range map[string]int{
+		`{"name": null}`:      http.StatusBadRequest,
+		`{"gender": "robot"}`: http.StatusBadRequest,
+		`{"password": "x"}`:   http.StatusBadRequest,
+		`[1, 2]`:              http.StatusBadRequest,
+	} {
+		if w := doRequestWithHeader(r, http.MethodPatch, path, body, "Content-Type", mergePatchContentType); w.Code != code {
+			t.Errorf("%s: expected %d, got %d", body, code, w.Code)
+		}
+	}
+
+	if w := doRequestWithHeader(r, http.MethodPatch, path, `{}`, "Content-Type", "text/plain"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain: expected 415, got %d", w.Code)
+	}
+}
+
+func TestPatchPerson_JSONPatch(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	_ = repo.Create(context.Background(), &models.Person{Name: "Ivan", Surname: "Petrov", Gender: "male", Age: 30})
+	path := "/api/v1/people/1"
+	patch := func(body string) int {
+		return doRequestWithHeader(r, http.MethodPatch, path, body, "Content-Type", jsonPatchContentType).Code
+	}
+
+	if code := patch(`[
+		{"op": "test", "path": "/age", "value": 30},
+		{"op": "test", "path": "/nationality", "value": null},
+		{"op": "replace", "path": "/age", "value": 31},
+		{"op": "test", "path": "/age", "value": 31},
+		{"op": "remove", "path": "/gender"}
+	]`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	got, _ := repo.Get(context.Background(), 1, false)
+	if got.Age != 31 || got.Gender != "" {
+		t.Fatalf("unexpected person: %+v", got)
+	}
+
+	// Не выполненный test отменяет весь патч
+	if code := patch(`[{"op": "replace", "path": "/age", "value": 40}, {"op": "test", "path": "/name", "value": "Petr"}]`); code != http.StatusConflict {
+		t.Errorf("failed test: expected 409, got %d", code)
+	}
+	if code := patch(`[{"op": "replace", "path": "/age", "value": 40}, {"op": "test", "path": "/age", "value": 41}]`); code != http.StatusConflict {
+		t.Errorf("failed test on pending value: expected 409, got %d", code)
+	}
+	if got, _ := repo.Get(context.Background(), 1, false); got.Age != 31 {
+		t.Errorf("patch must be applied atomically, got age %d", got.Age)
+	}
+
+	for _, body := range []string{
+		`[{"op": "add", "path": "/age", "value": 40}]`,
+		`[{"op": "remove", "path": "/surname"}]`,
+		`[{"op": "replace", "path": "/password", "value": "x"}]`,
+		`[{"op": "replace", "path": "/age"}]`,
+		`[{"op": "replace", "path": "/age", "value": 500}]`,
+		`[{"op": "test", "path": "/age", "value": 31}]`,
+	} {
+		if code := patch(body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, code)
+		}
+	}
+}
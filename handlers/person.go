@@ -2,11 +2,10 @@ package handlers
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"go-people-api/db"
 	"go-people-api/log"
 	"go-people-api/models"
+	"go-people-api/repository"
 	"net/http"
 	"strconv"
 	"time"
@@ -19,14 +18,35 @@ type PersonService interface {
 	Enrich(ctx context.Context, name string) (*models.Person, error)
 }
 
+// EnrichmentQueue очередь фонового обогащения
+type EnrichmentQueue interface {
+	// CreateAndEnqueue сохраняет людей и задачи на их обогащение атомарно;
+	// ошибки отдельных записей возвращаются по индексам, как в CreateBatch
+	CreateAndEnqueue(ctx context.Context, people []*models.Person) ([]error, error)
+}
+
 var (
-	personService PersonService
+	personService    PersonService
+	personRepository repository.PersonRepository
+	enrichmentQueue  EnrichmentQueue
+	asyncByDefault   bool
 )
 
 func SetPersonService(service PersonService) {
 	personService = service
 }
 
+func SetPersonRepository(repo repository.PersonRepository) {
+	personRepository = repo
+}
+
+// SetEnrichmentQueue включает фоновое обогащение; asyncDefault задаёт режим,
+// когда клиент не передал параметр async
+func SetEnrichmentQueue(queue EnrichmentQueue, asyncDefault bool) {
+	enrichmentQueue = queue
+	asyncByDefault = asyncDefault
+}
+
 func CreatePerson(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 4*time.Second)
 	defer cancel()
@@ -34,7 +54,7 @@ func CreatePerson(c *gin.Context) {
 	var input models.Person
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid input")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "validation_error",
 			Message: "Invalid input data",
 			Details: err.Error(),
@@ -43,58 +63,31 @@ func CreatePerson(c *gin.Context) {
 	}
 
 	if input.Name == "" || input.Surname == "" {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "validation_error",
 			Message: "Name and surname are required",
 		})
 		return
 	}
 
+	if useAsyncEnrichment(c) {
+		createPersonAsync(c, ctx, &input)
+		return
+	}
+
 	enriched, err := personService.Enrich(ctx, input.Name)
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Partial enrichment failure")
 
 	}
 
-	result := mergePersonData(&input, enriched)
-
-	dbConn, err := db.GetDB()
+	result := models.MergeEnrichment(&input, enriched)
+	result.EnrichmentStatus = models.EnrichmentCompleted
 	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
-		})
-		return
+		result.EnrichmentStatus = models.EnrichmentFailed
 	}
 
-	query := `
-		INSERT INTO people 
-		(name, surname, patronymic, gender, age, nationality)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
-	`
-
-	var gender *string
-	if result.Gender != "" {
-		gender = &result.Gender
-	}
-
-	var nationality *string
-	if result.Nationality != "" {
-		nationality = &result.Nationality
-	}
-
-	err = dbConn.QueryRowContext(ctx, query,
-		result.Name,
-		result.Surname,
-		result.Patronymic,
-		gender,
-		result.Age,
-		nationality,
-	).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt)
-
-	if err != nil {
+	if err := personRepository.Create(ctx, result); err != nil {
 		handleDatabaseError(c, ctx, err, "Failed to create person record")
 		return
 	}
@@ -102,22 +95,29 @@ func CreatePerson(c *gin.Context) {
 	c.JSON(http.StatusCreated, result)
 }
 
-func mergePersonData(input, enriched *models.Person) *models.Person {
-	if enriched == nil {
-		return input
+func useAsyncEnrichment(c *gin.Context) bool {
+	if enrichmentQueue == nil {
+		return false
 	}
-
-	result := *input
-	if enriched.Age > 0 && input.Age == 0 {
-		result.Age = enriched.Age
+	if async, err := strconv.ParseBool(c.Query("async")); err == nil {
+		return async
 	}
-	if enriched.Gender != "" && input.Gender == "" {
-		result.Gender = enriched.Gender
+	return asyncByDefault
+}
+
+// createPersonAsync сохраняет человека сразу вместе с задачей на обогащение
+func createPersonAsync(c *gin.Context, ctx context.Context, input *models.Person) {
+	input.EnrichmentStatus = models.EnrichmentPending
+	errs, err := enrichmentQueue.CreateAndEnqueue(ctx, []*models.Person{input})
+	if err == nil {
+		err = errs[0]
 	}
-	if enriched.Nationality != "" && input.Nationality == "" {
-		result.Nationality = enriched.Nationality
+	if err != nil {
+		handleDatabaseError(c, ctx, err, "Failed to create person record")
+		return
 	}
-	return &result
+
+	c.JSON(http.StatusAccepted, input)
 }
 
 func GetPeople(c *gin.Context) {
@@ -127,7 +127,7 @@ func GetPeople(c *gin.Context) {
 	var filter models.PersonFilter
 	if err := c.ShouldBindQuery(&filter); err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid filter params")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "validation_error",
 			Message: "Invalid filter parameters",
 			Details: err.Error(),
@@ -135,45 +135,26 @@ func GetPeople(c *gin.Context) {
 		return
 	}
 
-	dbConn, err := db.GetDB()
-	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
-		})
-		return
-	}
-
-	query, args := buildFilterQuery(filter)
-	rows, err := dbConn.QueryContext(ctx, query, args...)
+	page, err := personRepository.List(ctx, filter)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+			log.WithContext(ctx).WithError(err).Warn("Invalid pagination params")
+			code := "validation_error"
+			if errors.Is(err, repository.ErrInvalidCursor) {
+				code = "invalid_cursor"
+			}
+			respondError(c, http.StatusBadRequest, models.ErrorResponse{
+				Error:   code,
+				Message: "Invalid pagination parameters",
+				Details: err.Error(),
+			})
+			return
+		}
 		handleDatabaseError(c, ctx, err, "Failed to fetch people")
 		return
 	}
-	defer rows.Close()
-
-	var people []models.Person
-	for rows.Next() {
-		var p models.Person
-		var gender, nationality sql.NullString
-		if err := rows.Scan(
-			&p.ID, &p.Name, &p.Surname, &p.Patronymic,
-			&p.Age, &gender, &nationality, &p.CreatedAt, &p.UpdatedAt,
-		); err != nil {
-			log.WithContext(ctx).WithError(err).Error("DB scan failed")
-			continue
-		}
-		if gender.Valid {
-			p.Gender = gender.String
-		}
-		if nationality.Valid {
-			p.Nationality = nationality.String
-		}
-		people = append(people, p)
-	}
 
-	c.JSON(http.StatusOK, people)
+	c.JSON(http.StatusOK, page)
 }
 
 func GetPersonByID(c *gin.Context) {
@@ -183,35 +164,18 @@ func GetPersonByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Person ID must be an integer",
 		})
 		return
 	}
 
-	dbConn, err := db.GetDB()
-	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
-		})
-		return
-	}
-
-	var person models.Person
-	var gender, nationality sql.NullString
-	query := `SELECT id, name, surname, patronymic, age, gender, nationality, created_at, updated_at 
-	          FROM people WHERE id = $1`
-	err = dbConn.QueryRowContext(ctx, query, id).Scan(
-		&person.ID, &person.Name, &person.Surname, &person.Patronymic,
-		&person.Age, &gender, &nationality, &person.CreatedAt, &person.UpdatedAt,
-	)
-
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+	person, err := personRepository.Get(ctx, id, includeDeleted)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
+		if errors.Is(err, repository.ErrNotFound) {
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
 				Error:   "not_found",
 				Message: "Person not found",
 			})
@@ -221,11 +185,11 @@ func GetPersonByID(c *gin.Context) {
 		return
 	}
 
-	if gender.Valid {
-		person.Gender = gender.String
-	}
-	if nationality.Valid {
-		person.Nationality = nationality.String
+	etag := personETag(person.Version)
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
 	}
 
 	c.JSON(http.StatusOK, person)
@@ -238,7 +202,7 @@ func UpdatePerson(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Person ID must be an integer",
 		})
@@ -248,7 +212,7 @@ func UpdatePerson(c *gin.Context) {
 	var input models.Person
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid input")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "validation_error",
 			Message: "Invalid input data",
 			Details: err.Error(),
@@ -256,43 +220,19 @@ func UpdatePerson(c *gin.Context) {
 		return
 	}
 
-	dbConn, err := db.GetDB()
-	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
-		})
+	ifVersion, ok := ifMatchVersion(c, ctx, id)
+	if !ok {
 		return
 	}
 
-	query := `
-		UPDATE people 
-		SET name = $1, surname = $2, patronymic = $3, age = $4, 
-		    gender = $5, nationality = $6
-		WHERE id = $7
-		RETURNING updated_at
-	`
-
-	var gender *string
-	if input.Gender != "" {
-		gender = &input.Gender
-	}
-
-	var nationality *string
-	if input.Nationality != "" {
-		nationality = &input.Nationality
-	}
-
-	var updatedAt time.Time
-	err = dbConn.QueryRowContext(ctx, query,
-		input.Name, input.Surname, input.Patronymic, input.Age,
-		gender, nationality, id,
-	).Scan(&updatedAt)
-
+	person, err := personRepository.Update(ctx, id, &input, ifVersion)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			respondPreconditionFailed(c)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
 				Error:   "not_found",
 				Message: "Person not found",
 			})
@@ -302,9 +242,11 @@ func UpdatePerson(c *gin.Context) {
 		return
 	}
 
+	setPersonETag(c, person)
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "success",
-		"updated_at": updatedAt,
+		"updated_at": person.UpdatedAt,
+		"version":    person.Version,
 	})
 }
 
@@ -315,41 +257,47 @@ func PatchPerson(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Person ID must be an integer",
 		})
 		return
 	}
 
-	var input models.UpdatePersonRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
-		log.WithContext(ctx).WithError(err).Warn("Invalid input")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+	input, perr := parsePatch(c)
+	if perr != nil {
+		log.WithContext(ctx).Warnf("Invalid patch: %s %s", perr.resp.Message, perr.resp.Details)
+		respondError(c, perr.status, perr.resp)
+		return
+	}
+
+	ifVersion, ok := ifMatchVersion(c, ctx, id)
+	if !ok {
+		return
+	}
+
+	person, err := personRepository.Patch(ctx, id, input, ifVersion)
+	if errors.Is(err, repository.ErrNoFields) {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "validation_error",
-			Message: "Invalid input data",
-			Details: err.Error(),
+			Message: "No fields to update",
 		})
 		return
 	}
-
-	dbConn, err := db.GetDB()
-	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		respondPreconditionFailed(c)
+		return
+	}
+	if errors.Is(err, repository.ErrTestFailed) {
+		respondError(c, http.StatusConflict, models.ErrorResponse{
+			Error:   "test_failed",
+			Message: "JSON patch test operation failed",
 		})
 		return
 	}
-
-	query, args := buildPartialUpdateQuery(id, input)
-	var updatedAt time.Time
-	err = dbConn.QueryRowContext(ctx, query, args...).Scan(&updatedAt)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
+		if errors.Is(err, repository.ErrNotFound) {
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
 				Error:   "not_found",
 				Message: "Person not found",
 			})
@@ -359,9 +307,11 @@ func PatchPerson(c *gin.Context) {
 		return
 	}
 
+	setPersonETag(c, person)
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "success",
-		"updated_at": updatedAt,
+		"updated_at": person.UpdatedAt,
+		"version":    person.Version,
 	})
 }
 
@@ -372,42 +322,76 @@ func DeletePerson(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Person ID must be an integer",
 		})
 		return
 	}
 
-	dbConn, err := db.GetDB()
-	if err != nil {
-		log.WithContext(ctx).WithError(err).Error("Failed to get DB connection")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "database_error",
-			Message: "Database unavailable",
-		})
+	ifVersion, ok := ifMatchVersion(c, ctx, id)
+	if !ok {
 		return
 	}
 
-	result, err := dbConn.ExecContext(ctx, "DELETE FROM people WHERE id = $1", id)
-	if err != nil {
+	if err := personRepository.Delete(ctx, id, ifVersion); err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			respondPreconditionFailed(c)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Person not found",
+			})
+			return
+		}
 		handleDatabaseError(c, ctx, err, "Failed to delete person")
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:   "not_found",
-			Message: "Person not found",
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "success",
+		"rows_affected": 1,
+	})
+}
+
+// RestorePerson снимает пометку удаления с человека
+func RestorePerson(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("Invalid ID format")
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Person ID must be an integer",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":        "success",
-		"rows_affected": rowsAffected,
-	})
+	person, err := personRepository.Restore(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			respondError(c, http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Person not found",
+			})
+		case errors.Is(err, repository.ErrNotDeleted):
+			respondError(c, http.StatusConflict, models.ErrorResponse{
+				Error:   "not_deleted",
+				Message: "Person is not deleted",
+			})
+		default:
+			handleDatabaseError(c, ctx, err, "Failed to restore person")
+		}
+		return
+	}
+
+	setPersonETag(c, person)
+	c.JSON(http.StatusOK, person)
 }
 
 func handleDatabaseError(c *gin.Context, ctx context.Context, err error, message string) {
@@ -415,7 +399,7 @@ func handleDatabaseError(c *gin.Context, ctx context.Context, err error, message
 
 	var pgErr *pq.Error
 	if errors.As(err, &pgErr) {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
 			Error:   "database_error",
 			Message: message,
 			Details: pgErr.Message,
@@ -423,120 +407,8 @@ func handleDatabaseError(c *gin.Context, ctx context.Context, err error, message
 		return
 	}
 
-	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+	respondError(c, http.StatusInternalServerError, models.ErrorResponse{
 		Error:   "database_error",
 		Message: message,
 	})
 }
-
-func buildFilterQuery(filter models.PersonFilter) (string, []interface{}) {
-	query := `SELECT id, name, surname, patronymic, age, gender, nationality, created_at, updated_at 
-              FROM people WHERE 1=1`
-	var args []interface{}
-	argPos := 1
-
-	if filter.Name != "" {
-		query += " AND name ILIKE $" + strconv.Itoa(argPos)
-		args = append(args, "%"+filter.Name+"%")
-		argPos++
-	}
-	if filter.Surname != "" {
-		query += " AND surname ILIKE $" + strconv.Itoa(argPos)
-		args = append(args, "%"+filter.Surname+"%")
-		argPos++
-	}
-	if filter.AgeFrom != nil {
-		query += " AND age >= $" + strconv.Itoa(argPos)
-		args = append(args, *filter.AgeFrom)
-		argPos++
-	}
-	if filter.AgeTo != nil {
-		query += " AND age <= $" + strconv.Itoa(argPos)
-		args = append(args, *filter.AgeTo)
-		argPos++
-	}
-	if filter.Gender != "" {
-		query += " AND gender = $" + strconv.Itoa(argPos)
-		args = append(args, filter.Gender)
-		argPos++
-	}
-	if filter.Nationality != "" {
-		query += " AND nationality = $" + strconv.Itoa(argPos)
-		args = append(args, filter.Nationality)
-		argPos++
-	}
-
-	query += " ORDER BY created_at DESC"
-	return query, args
-}
-
-func buildPartialUpdateQuery(id int, input models.UpdatePersonRequest) (string, []interface{}) {
-	query := "UPDATE people SET "
-	var args []interface{}
-	argPos := 1
-	fields := 0
-
-	if input.Name != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "name = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Name)
-		argPos++
-		fields++
-	}
-	if input.Surname != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "surname = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Surname)
-		argPos++
-		fields++
-	}
-	if input.Patronymic != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "patronymic = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Patronymic)
-		argPos++
-		fields++
-	}
-	if input.Age != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "age = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Age)
-		argPos++
-		fields++
-	}
-	if input.Gender != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "gender = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Gender)
-		argPos++
-		fields++
-	}
-	if input.Nationality != nil {
-		if fields > 0 {
-			query += ", "
-		}
-		query += "nationality = $" + strconv.Itoa(argPos)
-		args = append(args, *input.Nationality)
-		argPos++
-		fields++
-	}
-
-	if fields == 0 {
-		return "", nil
-	}
-
-	query += ", updated_at = NOW() WHERE id = $" + strconv.Itoa(argPos) + " RETURNING updated_at"
-	args = append(args, id)
-
-	return query, args
-}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-people-api/models"
+	"go-people-api/repository"
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubPersonService struct {
+	person *models.Person
+	err    error
+}
+
+func (s stubPersonService) Enrich(_ context.Context, _ string) (*models.Person, error) {
+	return s.person, s.err
+}
+
+func setupTestRouter(t *testing.T, service PersonService) (*gin.Engine, *repository.MemoryPersonRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryPersonRepository()
+	SetPersonService(service)
+	SetPersonRepository(repo)
+
+	r := gin.New()
+	api := r.Group("/api/v1")
+	api.POST("/people", CreatePerson)
+	api.GET("/people", GetPeople)
+	api.GET("/people/:id", GetPersonByID)
+	api.PUT("/people/:id", UpdatePerson)
+	api.PATCH("/people/:id", PatchPerson)
+	api.DELETE("/people/:id", DeletePerson)
+	return r, repo
+}
+
+func doRequest(r *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreatePerson_Enriched(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{
+		person: &models.Person{Age: 42, Gender: "male", Nationality: "RU"},
+	})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/people", map[string]string{
+		"name": "Dmitriy", "surname": "Ushakov",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var person models.Person
+	if err := json.Unmarshal(w.Body.Bytes(), &person); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if person.ID == 0 || person.Age != 42 || person.Gender != "male" || person.Nationality != "RU" {
+		t.Errorf("unexpected person: %+v", person)
+	}
+}
+
+func TestCreatePerson_PartialEnrichment(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{
+		person: &models.Person{Age: 30},
+		err:    errors.New("partial enrichment failure"),
+	})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/people", map[string]string{
+		"name": "Ivan", "surname": "Petrov", "gender": "male",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var person models.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &person)
+	if person.Age != 30 || person.Gender != "male" {
+		t.Errorf("unexpected person: %+v", person)
+	}
+}
+
+func TestCreatePerson_ValidationError(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/people", map[string]string{"name": "Ivan"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestGetPersonByID_NotFound(t *testing.T) {
+	r, _ := setupTestRouter(t, stubPersonService{})
+
+	if w := doRequest(r, http.MethodGet, "/api/v1/people/42", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodGet, "/api/v1/people/abc", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestUpdatePatchDeletePerson(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	person := &models.Person{Name: "Ivan", Surname: "Petrov", Age: 20}
+	if err := repo.Create(context.Background(), person); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/people/1"
+
+	w := doRequest(r, http.MethodPut, path, map[string]interface{}{
+		"name": "Petr", "surname": "Ivanov", "age": 33,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(r, http.MethodPatch, path, map[string]interface{}{"nationality": "KZ"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(r, http.MethodPatch, path, map[string]interface{}{})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty PATCH: expected 400, got %d", w.Code)
+	}
+
+	got, _ := repo.Get(context.Background(), 1, false)
+	if got.Name != "Petr" || got.Age != 33 || got.Nationality != "KZ" {
+		t.Errorf("unexpected person after update: %+v", got)
+	}
+
+	if w = doRequest(r, http.MethodDelete, path, nil); w.Code != http.StatusOK {
+		t.Errorf("DELETE: expected 200, got %d", w.Code)
+	}
+	if w = doRequest(r, http.MethodDelete, path, nil); w.Code != http.StatusNotFound {
+		t.Errorf("second DELETE: expected 404, got %d", w.Code)
+	}
+}
+
+func TestGetPeople_Pagination(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	for _, age := range []int{50, 20, 40, 10, 30} {
+		_ = repo.Create(context.Background(), &models.Person{Name: "Anna", Surname: "Smirnova", Age: age})
+	}
+
+	var page models.PeoplePage
+	w := doRequest(r, http.MethodGet, "/api/v1/people?sort=age&limit=2", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if page.Total != 5 || len(page.Data) != 2 || page.Data[0].Age != 10 || page.Data[1].Age != 20 {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	if page.NextCursor == "" || page.PrevCursor != "" {
+		t.Fatalf("unexpected cursors on first page: %+v", page)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/people?sort=age&limit=2&cursor="+page.NextCursor, nil)
+	page = models.PeoplePage{}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if len(page.Data) != 2 || page.Data[0].Age != 30 || page.Data[1].Age != 40 {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/people?sort=age&limit=2&cursor="+page.PrevCursor, nil)
+	page = models.PeoplePage{}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if len(page.Data) != 2 || page.Data[0].Age != 10 || page.PrevCursor != "" {
+		t.Fatalf("unexpected previous page: %+v", page)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/people?sort=-age&offset=4", nil)
+	page = models.PeoplePage{}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if len(page.Data) != 1 || page.Data[0].Age != 10 || page.NextCursor != "" {
+		t.Fatalf("unexpected offset page: %+v", page)
+	}
+
+	if w = doRequest(r, http.MethodGet, "/api/v1/people?sort=password", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort: expected 400, got %d", w.Code)
+	}
+	if w = doRequest(r, http.MethodGet, "/api/v1/people?sort=-age&cursor="+page.PrevCursor+"x", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("broken cursor: expected 400, got %d", w.Code)
+	}
+}
+
+type stubQueue struct {
+	repo      *repository.MemoryPersonRepository
+	personIDs []int
+	err       error
+}
+
+func (q *stubQueue) CreateAndEnqueue(ctx context.Context, people []*models.Person) ([]error, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	errs, err := q.repo.CreateBatch(ctx, people)
+	for i, person := range people {
+		if err == nil && errs[i] == nil {
+			q.personIDs = append(q.personIDs, person.ID)
+		}
+	}
+	return errs, err
+}
+
+func TestCreatePerson_Async(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{err: errors.New("must not be called")})
+	queue := &stubQueue{repo: repo}
+	SetEnrichmentQueue(queue, false)
+	defer SetEnrichmentQueue(nil, false)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/people?async=true", map[string]string{
+		"name": "Ivan", "surname": "Petrov",
+	})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var person models.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &person)
+	if person.EnrichmentStatus != models.EnrichmentPending || len(queue.personIDs) != 1 || queue.personIDs[0] != person.ID {
+		t.Errorf("unexpected async result: %+v, queue %v", person, queue.personIDs)
+	}
+}
+
+func TestCreatePerson_AsyncQueueFailure(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	SetEnrichmentQueue(&stubQueue{repo: repo, err: errors.New("connection reset")}, true)
+	defer SetEnrichmentQueue(nil, false)
+
+	// Человек и задача сохраняются вместе: при ошибке клиент получает 5xx и может повторить запрос
+	w := doRequest(r, http.MethodPost, "/api/v1/people", map[string]string{"name": "Ivan", "surname": "Petrov"})
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if page, _ := repo.List(context.Background(), models.PersonFilter{}); page.Total != 0 {
+		t.Errorf("person must not be saved without a job, got %+v", page.Data)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	r.POST("/api/v1/people/:id/restore", RestorePerson)
+	_ = repo.Create(context.Background(), &models.Person{Name: "Ivan", Surname: "Petrov"})
+	_ = repo.Create(context.Background(), &models.Person{Name: "Anna", Surname: "Smirnova"})
+
+	if w := doRequest(r, http.MethodPost, "/api/v1/people/1/restore", nil); w.Code != http.StatusConflict {
+		t.Errorf("restore of active person: expected 409, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodDelete, "/api/v1/people/1", nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE: expected 200, got %d", w.Code)
+	}
+
+	if w := doRequest(r, http.MethodGet, "/api/v1/people/1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("deleted person: expected 404, got %d", w.Code)
+	}
+	w := doRequest(r, http.MethodGet, "/api/v1/people/1?include_deleted=true", nil)
+	var person models.Person
+	_ = json.Unmarshal(w.Body.Bytes(), &person)
+	if w.Code != http.StatusOK || person.DeletedAt == nil {
+		t.Errorf("include_deleted: expected deleted person, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(r, http.MethodPatch, "/api/v1/people/1", map[string]interface{}{"age": 30}); w.Code != http.StatusNotFound {
+		t.Errorf("PATCH of deleted person: expected 404, got %d", w.Code)
+	}
+
+	var page models.PeoplePage
+	_ = json.Unmarshal(doRequest(r, http.MethodGet, "/api/v1/people", nil).Body.Bytes(), &page)
+	if page.Total != 1 {
+		t.Errorf("expected deleted person to be hidden, got total %d", page.Total)
+	}
+	_ = json.Unmarshal(doRequest(r, http.MethodGet, "/api/v1/people?include_deleted=true", nil).Body.Bytes(), &page)
+	if page.Total != 2 {
+		t.Errorf("include_deleted: expected total 2, got %d", page.Total)
+	}
+
+	w = doRequest(r, http.MethodPost, "/api/v1/people/1/restore", nil)
+	person = models.Person{}
+	_ = json.Unmarshal(w.Body.Bytes(), &person)
+	if w.Code != http.StatusOK || person.DeletedAt != nil {
+		t.Fatalf("restore: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(r, http.MethodGet, "/api/v1/people/1", nil); w.Code != http.StatusOK {
+		t.Errorf("restored person: expected 200, got %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodPost, "/api/v1/people/42/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restore of unknown person: expected 404, got %d", w.Code)
+	}
+}
+
+func TestErrorResponse_IncludesRequestID(t *testing.T) {
+	setupTestRouter(t, stubPersonService{})
+	r := gin.New()
+	r.Use(requestid.Middleware())
+	r.GET("/api/v1/people/:id", GetPersonByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/people/404", nil)
+	req.Header.Set(requestid.Header, "req-404")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.RequestID != "req-404" {
+		t.Errorf("expected request_id in error body, got %q", resp.RequestID)
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/repository"
+	"go-people-api/transfer"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxImportSize ограничивает размер загружаемого файла
+	maxImportSize = 32 << 20
+	// exportFlushEvery как часто выгрузка сбрасывается клиенту
+	exportFlushEvery = 500
+)
+
+// ExportPeople выгружает людей в CSV или NDJSON с теми же фильтрами и сортировкой, что и GetPeople.
+// Строки читаются из базы порциями и сразу пишутся в ответ.
+func ExportPeople(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid export format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var filter models.PersonFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("Invalid filter params")
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid filter parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var (
+		writer  transfer.Writer
+		written int
+	)
+	err = personRepository.Stream(ctx, filter, func(person *models.Person) error {
+		if writer == nil {
+			writer = startExport(c, format)
+		}
+		if err := writer.Write(person); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err != nil && writer == nil {
+		if errors.Is(err, repository.ErrInvalidSort) {
+			respondError(c, http.StatusBadRequest, models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid sort parameter",
+				Details: err.Error(),
+			})
+			return
+		}
+		handleDatabaseError(c, ctx, err, "Failed to export people")
+		return
+	}
+	if err != nil {
+		// Заголовки уже отправлены: остаётся оборвать выгрузку
+		log.WithContext(ctx).WithError(err).Error("Export interrupted")
+		c.Abort()
+		return
+	}
+
+	if writer == nil {
+		writer = startExport(c, format)
+	}
+	if err := writer.Flush(); err != nil {
+		log.WithContext(ctx).WithError(err).Error("Failed to flush export")
+	}
+	log.WithContext(ctx).Infof("Exported %d people as %s", written, format)
+}
+
+func startExport(c *gin.Context, format transfer.Format) transfer.Writer {
+	filename := fmt.Sprintf("people-%s.%s", time.Now().Format("20060102-150405"), format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	return transfer.NewWriter(c.Writer, format)
+}
+
+// ImportPeople загружает людей из CSV или NDJSON (тело запроса или поле file в multipart/form-data).
+// Некорректные строки попадают в отчёт, остальные сохраняются; с dry_run=true ничего не сохраняется.
+func ImportPeople(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	body, format, err := importSource(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_file",
+			Message: "Invalid import file",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer body.Close()
+
+	report := models.ImportReport{DryRun: dryRun, Errors: []models.ImportRowError{}}
+	var (
+		people []*models.Person
+		lines  []int
+	)
+
+	reader := transfer.NewReader(body, format)
+	for {
+		person, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *transfer.RowError
+		if errors.As(err, &rowErr) {
+			report.Total++
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Line: rowErr.Line,
+				Error: models.ErrorResponse{
+					Error:   "parse_error",
+					Message: "Invalid row",
+					Details: rowErr.Err.Error(),
+				},
+			})
+			continue
+		}
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Invalid import file")
+			respondError(c, http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_file",
+				Message: "Invalid import file",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		report.Total++
+		if errResp := validateBatchItem(person); errResp != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Line: line, Error: *errResp})
+			continue
+		}
+		people = append(people, person)
+		lines = append(lines, line)
+	}
+
+	if dryRun {
+		report.Imported = len(people)
+	} else {
+		for start := 0; start < len(people); start += maxBatchSize {
+			end := min(start+maxBatchSize, len(people))
+			createErrs, err := personRepository.CreateBatch(ctx, people[start:end])
+			if err != nil {
+				handleDatabaseError(c, ctx, err, "Failed to import people")
+				return
+			}
+			for i, createErr := range createErrs {
+				if createErr != nil {
+					report.Errors = append(report.Errors, models.ImportRowError{
+						Line: lines[start+i], Error: *batchDatabaseError(createErr),
+					})
+					continue
+				}
+				report.Imported++
+			}
+		}
+	}
+	report.Failed = len(report.Errors)
+
+	log.WithContext(ctx).Infof("Import finished: %d rows, %d imported, %d failed, dry run %t",
+		report.Total, report.Imported, report.Failed, dryRun)
+
+	status := http.StatusOK
+	switch {
+	case report.Failed > 0:
+		status = http.StatusMultiStatus
+	case !dryRun:
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+// importSource возвращает поток с содержимым файла и его формат.
+// Формат берётся из параметра format, иначе из Content-Type или расширения файла.
+func importSource(c *gin.Context) (io.ReadCloser, transfer.Format, error) {
+	explicit := c.Query("format")
+	format, err := transfer.ParseFormat(explicit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if detected, ok := transfer.FormatFromContentType(c.GetHeader("Content-Type")); ok && explicit == "" {
+			format = detected
+		}
+		return c.Request.Body, format, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", fmt.Errorf("file field is required: %w", err)
+	}
+	if explicit == "" {
+		format = formatFromUpload(header)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	return file, format, nil
+}
+
+func formatFromUpload(header *multipart.FileHeader) transfer.Format {
+	if format, ok := transfer.FormatFromContentType(header.Header.Get("Content-Type")); ok {
+		return format
+	}
+	if format, err := transfer.ParseFormat(strings.TrimPrefix(filepath.Ext(header.Filename), ".")); err == nil {
+		return format
+	}
+	return transfer.FormatCSV
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-people-api/models"
+)
+
+func TestExportPeople(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	r.GET("/api/v1/export", ExportPeople)
+	for _, p := range []models.Person{
+		{Name: "Ivan", Surname: "Petrov", Age: 40, Gender: "male"},
+		{Name: "Anna", Surname: "Smirnova", Age: 20, Gender: "female"},
+		{Name: "Olga", Surname: "Ivanova", Age: 30, Gender: "female"},
+	} {
+		_ = repo.Create(context.Background(), &p)
+	}
+
+	w := doRequest(r, http.MethodGet, "/api/v1/export?gender=female&sort=age", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "id,name") ||
+		!strings.HasPrefix(lines[1], "2,Anna") || !strings.HasPrefix(lines[2], "3,Olga") {
+		t.Errorf("unexpected CSV:\n%s", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/export?format=ndjson&name=ivan", nil)
+	lines = strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"name":"Ivan"`) {
+		t.Errorf("unexpected NDJSON:\n%s", w.Body.String())
+	}
+
+	if w = doRequest(r, http.MethodGet, "/api/v1/export?sort=password", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort: expected 400, got %d", w.Code)
+	}
+	if w = doRequest(r, http.MethodGet, "/api/v1/export?format=xml", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: expected 400, got %d", w.Code)
+	}
+}
+
+func TestImportPeople(t *testing.T) {
+	r, repo := setupTestRouter(t, stubPersonService{})
+	r.POST("/api/v1/import", ImportPeople)
+
+	csv := "name,surname,age,gender\nIvan,Petrov,30,male\nAnna,,25,female\nOlga,Ivanova,abc,\nPetr,Sidorov,,\n"
+	importCSV := func(query string) (*httptest.ResponseRecorder, models.ImportReport) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/import"+query, strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var report models.ImportReport
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w, report
+	}
+
+	w, report := importCSV("?dry_run=true")
+	if w.Code != http.StatusMultiStatus || !report.DryRun || report.Total != 4 || report.Imported != 2 || report.Failed != 2 {
+		t.Fatalf("unexpected dry run report (%d): %+v", w.Code, report)
+	}
+	if report.Errors[0].Line != 3 || report.Errors[1].Line != 4 {
+		t.Errorf("unexpected error lines: %+v", report.Errors)
+	}
+	if page, _ := repo.List(context.Background(), models.PersonFilter{}); page.Total != 0 {
+		t.Fatalf("dry run must not store people, got %d", page.Total)
+	}
+
+	_, report = importCSV("")
+	if report.Imported != 2 || report.DryRun {
+		t.Fatalf("unexpected import report: %+v", report)
+	}
+	if page, _ := repo.List(context.Background(), models.PersonFilter{Sort: "id"}); page.Total != 2 || page.Data[0].Age != 30 {
+		t.Errorf("unexpected stored people: %+v", page)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/import", strings.NewReader("name,password\n"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid header: expected 400, got %d", w.Code)
+	}
+}
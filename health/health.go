@@ -0,0 +1,155 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout время на одну проверку, если не задано своё
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc проверяет зависимость; ошибка — зависимость недоступна
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	timeout  time.Duration
+	critical bool
+	fn       CheckFunc
+}
+
+// Checker выполняет проверки готовности. Критичные проверки (БД) переводят /readyz в 503,
+// некритичные (внешние API) только понижают статус до degraded.
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add регистрирует проверку; timeout <= 0 — DefaultTimeout
+func (hc *Checker) Add(name string, timeout time.Duration, critical bool, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.checks = append(hc.checks, check{name: name, timeout: timeout, critical: critical, fn: fn})
+}
+
+// SetShuttingDown переводит /readyz в 503, чтобы балансировщик перестал слать запросы до остановки сервера
+func (hc *Checker) SetShuttingDown() {
+	hc.shuttingDown.Store(true)
+}
+
+// Run выполняет все проверки параллельно, каждую со своим таймаутом
+func (hc *Checker) Run(ctx context.Context) models.HealthReport {
+	report := models.HealthReport{Status: models.HealthOK, CheckedAt: time.Now().UTC()}
+	if hc.shuttingDown.Load() {
+		report.Status = models.HealthShuttingDown
+		return report
+	}
+
+	hc.mu.RLock()
+	checks := append([]check(nil), hc.checks...)
+	hc.mu.RUnlock()
+
+	report.Checks = make([]models.HealthCheck, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = chk.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		switch {
+		case result.Status == models.HealthOK:
+		case result.Critical:
+			report.Status = models.HealthFail
+		case report.Status == models.HealthOK:
+			report.Status = models.HealthDegraded
+		}
+	}
+	return report
+}
+
+func (c check) run(ctx context.Context) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	result := models.HealthCheck{
+		Name:      c.name,
+		Status:    models.HealthOK,
+		Critical:  c.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = models.HealthFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness отвечает 200, пока процесс обслуживает запросы; зависимости не проверяются,
+// чтобы недоступная БД не приводила к перезапуску контейнера
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.HealthReport{Status: models.HealthOK, CheckedAt: time.Now().UTC()})
+	}
+}
+
+// Readiness отвечает 200, если критичные зависимости доступны, иначе 503
+func (hc *Checker) Readiness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := hc.Run(c.Request.Context())
+		status := http.StatusOK
+		if report.Status == models.HealthFail || report.Status == models.HealthShuttingDown {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
+
+// DialCheck проверяет, что хост из адреса принимает TCP-соединения. Запрос к самому API
+// не отправляется, чтобы проверки не расходовали квоту провайдера.
+func DialCheck(rawURL string) CheckFunc {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid url %q", rawURL)
+		}
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
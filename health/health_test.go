@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func readiness(t *testing.T, checker *Checker) (int, models.HealthReport) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", checker.Readiness())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report models.HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid report %s: %v", w.Body.String(), err)
+	}
+	return w.Code, report
+}
+
+func TestReadiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("down") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	checker := NewChecker()
+	checker.Add("database", time.Second, true, ok)
+	checker.Add("enrichment.age", 10*time.Millisecond, false, hang)
+	checker.Add("enrichment.gender", 0, false, down)
+
+	code, report := readiness(t, checker)
+	if code != http.StatusOK || report.Status != models.HealthDegraded || len(report.Checks) != 3 {
+		t.Fatalf("expected degraded 200, got %d %+v", code, report)
+	}
+	if age := report.Checks[1]; age.Status != models.HealthFail || age.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected per-check timeout, got %+v", age)
+	}
+
+	checker.Add("cache", 0, true, down)
+	if code, report = readiness(t, checker); code != http.StatusServiceUnavailable || report.Status != models.HealthFail {
+		t.Errorf("expected failed critical check to return 503, got %d %s", code, report.Status)
+	}
+
+	checker.SetShuttingDown()
+	if code, report = readiness(t, checker); code != http.StatusServiceUnavailable || report.Status != models.HealthShuttingDown {
+		t.Errorf("expected 503 during shutdown, got %d %s", code, report.Status)
+	}
+}
+
+func TestDialCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("dial check must not send requests")
+	}))
+	url := server.URL + "/?name=healthcheck"
+
+	if err := DialCheck(url)(context.Background()); err != nil {
+		t.Fatalf("expected reachable host, got %v", err)
+	}
+	server.Close()
+	if err := DialCheck(url)(context.Background()); err == nil {
+		t.Error("expected error for closed server")
+	}
+}
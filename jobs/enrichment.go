@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/repository"
+	"go-people-api/tracing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNoJobs очередь пуста
+var ErrNoJobs = errors.New("no jobs available")
+
+// Job задача на обогащение человека
+type Job struct {
+	ID          int64
+	PersonID    int
+	Name        string
+	Attempts    int
+	MaxAttempts int
+}
+
+// JobStore хранилище очереди задач обогащения
+type JobStore interface {
+	Enqueue(ctx context.Context, personID int, name string) error
+	// Claim забирает следующую готовую задачу; ErrNoJobs, если таких нет
+	Claim(ctx context.Context) (*Job, error)
+	Complete(ctx context.Context, job *Job) error
+	// Retry возвращает задачу в очередь с запуском не раньше runAt
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error
+	// Bury переводит задачу в dead-letter
+	Bury(ctx context.Context, job *Job, cause error) error
+}
+
+type Enricher interface {
+	Enrich(ctx context.Context, name string) (*models.Person, error)
+}
+
+// WorkerConfig параметры пула обработчиков
+type WorkerConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+}
+
+var DefaultWorkerConfig = WorkerConfig{
+	Workers:      4,
+	PollInterval: time.Second,
+	JobTimeout:   10 * time.Second,
+	RetryBase:    10 * time.Second,
+	RetryMax:     10 * time.Minute,
+}
+
+// EnrichmentWorker пул обработчиков, обогащающих людей в фоне
+type EnrichmentWorker struct {
+	store    JobStore
+	enricher Enricher
+	people   repository.PersonRepository
+	config   WorkerConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewEnrichmentWorker(store JobStore, enricher Enricher, people repository.PersonRepository, config WorkerConfig) *EnrichmentWorker {
+	return &EnrichmentWorker{
+		store:    store,
+		enricher: enricher,
+		people:   people,
+		config:   config,
+	}
+}
+
+// Start запускает обработчики; они работают до вызова Stop
+func (w *EnrichmentWorker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < w.config.Workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.run(ctx)
+		}()
+	}
+	log.WithContext(ctx).Infof("Enrichment worker pool started with %d workers", w.config.Workers)
+}
+
+// Stop останавливает обработчики и ждёт завершения текущих задач
+func (w *EnrichmentWorker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *EnrichmentWorker) run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Разбираем очередь, пока в ней есть задачи, затем ждём следующего опроса
+		for w.processNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNext обрабатывает одну задачу и сообщает, была ли она
+func (w *EnrichmentWorker) processNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	job, err := w.store.Claim(ctx)
+	if errors.Is(err, ErrNoJobs) {
+		return false
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.WithContext(ctx).WithError(err).Error("Failed to claim enrichment job")
+		}
+		return false
+	}
+
+	w.process(job)
+	return true
+}
+
+// process выполняется с собственным контекстом, чтобы остановка пула
+// не обрывала уже начатую задачу
+func (w *EnrichmentWorker) process(job *Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.JobTimeout)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "enrichment.job",
+		attribute.Int64("job.id", job.ID),
+		attribute.Int("job.attempt", job.Attempts),
+		attribute.Int("person.id", job.PersonID),
+	)
+	defer span.End()
+
+	logger := log.WithContext(ctx).WithFields(logrus.Fields{
+		"job_id":    job.ID,
+		"person_id": job.PersonID,
+		"attempt":   job.Attempts,
+	})
+
+	enriched, enrichErr := w.enricher.Enrich(ctx, job.Name)
+	if enrichErr == nil {
+		err := w.people.ApplyEnrichment(ctx, job.PersonID, enriched, models.EnrichmentCompleted)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			w.fail(ctx, job, err, logger)
+			return
+		}
+		if err := w.store.Complete(ctx, job); err != nil {
+			logger.WithError(err).Error("Failed to complete enrichment job")
+		}
+		return
+	}
+
+	// Частичный результат сохраняем сразу, недостающие поля дозаполнит следующая попытка
+	status := models.EnrichmentPending
+	if job.Attempts >= job.MaxAttempts {
+		status = models.EnrichmentFailed
+	}
+	if err := w.people.ApplyEnrichment(ctx, job.PersonID, enriched, status); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		logger.WithError(err).Error("Failed to save partial enrichment")
+	}
+	w.fail(ctx, job, enrichErr, logger)
+}
+
+func (w *EnrichmentWorker) fail(ctx context.Context, job *Job, cause error, logger *logrus.Entry) {
+	if job.Attempts >= job.MaxAttempts {
+		logger.Warnf("Enrichment job moved to dead letter after %d attempts: %v", job.Attempts, cause)
+		if err := w.store.Bury(ctx, job, cause); err != nil {
+			logger.WithError(err).Error("Failed to bury enrichment job")
+		}
+		return
+	}
+
+	runAt := time.Now().Add(w.retryDelay(job.Attempts))
+	logger.Warnf("Enrichment job failed, retry at %s: %v", runAt.Format(time.RFC3339), cause)
+	if err := w.store.Retry(ctx, job, runAt, cause); err != nil {
+		logger.WithError(err).Error("Failed to reschedule enrichment job")
+	}
+}
+
+func (w *EnrichmentWorker) retryDelay(attempt int) time.Duration {
+	delay := w.config.RetryBase << (attempt - 1)
+	if delay <= 0 || delay > w.config.RetryMax {
+		return w.config.RetryMax
+	}
+	return delay
+}
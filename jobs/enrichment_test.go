@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+	"go-people-api/repository"
+)
+
+// memoryJobStore очередь в памяти для тестов
+type memoryJobStore struct {
+	mu     sync.Mutex
+	jobs   []*Job
+	status map[int64]string
+	nextID int64
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{status: make(map[int64]string)}
+}
+
+func (s *memoryJobStore) Enqueue(_ context.Context, personID int, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.jobs = append(s.jobs, &Job{ID: s.nextID, PersonID: personID, Name: name, MaxAttempts: 2})
+	s.status[s.nextID] = "queued"
+	return nil
+}
+
+func (s *memoryJobStore) Claim(_ context.Context) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if s.status[job.ID] == "queued" {
+			s.status[job.ID] = "running"
+			job.Attempts++
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, ErrNoJobs
+}
+
+func (s *memoryJobStore) set(job *Job, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[job.ID] = status
+	return nil
+}
+
+func (s *memoryJobStore) Complete(_ context.Context, job *Job) error { return s.set(job, "done") }
+func (s *memoryJobStore) Retry(_ context.Context, job *Job, _ time.Time, _ error) error {
+	return s.set(job, "queued")
+}
+func (s *memoryJobStore) Bury(_ context.Context, job *Job, _ error) error { return s.set(job, "dead") }
+
+type stubEnricher struct {
+	person *models.Person
+	err    error
+}
+
+func (e stubEnricher) Enrich(_ context.Context, _ string) (*models.Person, error) {
+	return e.person, e.err
+}
+
+func TestEnrichmentWorker_Completes(t *testing.T) {
+	ctx := context.Background()
+	people := repository.NewMemoryPersonRepository()
+	person := &models.Person{Name: "Ivan", Surname: "Petrov", EnrichmentStatus: models.EnrichmentPending}
+	_ = people.Create(ctx, person)
+
+	store := newMemoryJobStore()
+	_ = store.Enqueue(ctx, person.ID, person.Name)
+
+	worker := NewEnrichmentWorker(store, stubEnricher{person: &models.Person{Age: 35, Gender: "male"}}, people, DefaultWorkerConfig)
+	if !worker.processNext(ctx) {
+		t.Fatal("expected a job to be processed")
+	}
+
+	got, _ := people.Get(ctx, person.ID, false)
+	if got.Age != 35 || got.Gender != "male" || got.EnrichmentStatus != models.EnrichmentCompleted {
+		t.Errorf("unexpected person: %+v", got)
+	}
+	if store.status[1] != "done" {
+		t.Errorf("expected job to be done, got %s", store.status[1])
+	}
+	if worker.processNext(ctx) {
+		t.Error("expected queue to be empty")
+	}
+}
+
+func TestEnrichmentWorker_DeadLetter(t *testing.T) {
+	ctx := context.Background()
+	people := repository.NewMemoryPersonRepository()
+	person := &models.Person{Name: "Ivan", Surname: "Petrov", EnrichmentStatus: models.EnrichmentPending}
+	_ = people.Create(ctx, person)
+
+	store := newMemoryJobStore()
+	_ = store.Enqueue(ctx, person.ID, person.Name)
+
+	enricher := stubEnricher{person: &models.Person{Age: 35}, err: errors.New("partial enrichment failure")}
+	worker := NewEnrichmentWorker(store, enricher, people, DefaultWorkerConfig)
+
+	worker.processNext(ctx)
+	got, _ := people.Get(ctx, person.ID, false)
+	if store.status[1] != "queued" || got.EnrichmentStatus != models.EnrichmentPending || got.Age != 35 {
+		t.Fatalf("expected retry with partial data, got job %s, person %+v", store.status[1], got)
+	}
+
+	worker.processNext(ctx)
+	got, _ = people.Get(ctx, person.ID, false)
+	if store.status[1] != "dead" || got.EnrichmentStatus != models.EnrichmentFailed {
+		t.Errorf("expected dead letter, got job %s, person %+v", store.status[1], got)
+	}
+}
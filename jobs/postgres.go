@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-people-api/models"
+	"go-people-api/repository"
+)
+
+// PostgresJobStore очередь задач в таблице enrichment_jobs.
+// Задачи забираются через SELECT ... FOR UPDATE SKIP LOCKED, поэтому
+// обработчики нескольких экземпляров сервиса не конкурируют за одну задачу.
+type PostgresJobStore struct {
+	db          *sql.DB
+	maxAttempts int
+	lease       time.Duration
+}
+
+// NewPostgresJobStore создаёт очередь; задача в статусе running дольше lease
+// считается брошенной (обработчик упал) и снова выдаётся
+func NewPostgresJobStore(db *sql.DB, maxAttempts int, lease time.Duration) *PostgresJobStore {
+	return &PostgresJobStore{db: db, maxAttempts: maxAttempts, lease: lease}
+}
+
+func (s *PostgresJobStore) Enqueue(ctx context.Context, personID int, name string) error {
+	return s.enqueue(ctx, s.db, personID, name)
+}
+
+func (s *PostgresJobStore) enqueue(ctx context.Context, exec execer, personID int, name string) error {
+	_, err := exec.ExecContext(ctx,
+		"INSERT INTO enrichment_jobs (person_id, name, max_attempts) VALUES ($1, $2, $3)",
+		personID, name, s.maxAttempts,
+	)
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// PostgresEnrichmentQueue сохраняет людей вместе с задачами на их обогащение
+type PostgresEnrichmentQueue struct {
+	people *repository.PostgresPersonRepository
+	jobs   *PostgresJobStore
+}
+
+func NewPostgresEnrichmentQueue(people *repository.PostgresPersonRepository, jobs *PostgresJobStore) *PostgresEnrichmentQueue {
+	return &PostgresEnrichmentQueue{people: people, jobs: jobs}
+}
+
+// CreateAndEnqueue вставляет людей и их задачи в одной транзакции, поэтому человек
+// не может остаться в статусе pending без задачи, даже если процесс упадёт посередине
+func (q *PostgresEnrichmentQueue) CreateAndEnqueue(ctx context.Context, people []*models.Person) ([]error, error) {
+	return q.people.CreateBatchWith(ctx, people, func(ctx context.Context, tx *sql.Tx, person *models.Person) error {
+		return q.jobs.enqueue(ctx, tx, person.ID, person.Name)
+	})
+}
+
+func (s *PostgresJobStore) Claim(ctx context.Context) (*Job, error) {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM enrichment_jobs
+			WHERE (status = 'queued' AND run_at <= NOW())
+			   OR (status = 'running' AND locked_at < NOW() - $1::interval)
+			ORDER BY run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, person_id, name, attempts, max_attempts
+	`
+
+	var job Job
+	err := s.db.QueryRowContext(ctx, query, s.lease.String()).Scan(
+		&job.ID, &job.PersonID, &job.Name, &job.Attempts, &job.MaxAttempts,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoJobs
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *PostgresJobStore) Complete(ctx context.Context, job *Job) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE enrichment_jobs SET status = 'done', locked_at = NULL, last_error = NULL, updated_at = NOW() WHERE id = $1",
+		job.ID,
+	)
+	return err
+}
+
+func (s *PostgresJobStore) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE enrichment_jobs SET status = 'queued', locked_at = NULL, run_at = $2, last_error = $3, updated_at = NOW() WHERE id = $1",
+		job.ID, runAt, cause.Error(),
+	)
+	return err
+}
+
+func (s *PostgresJobStore) Bury(ctx context.Context, job *Job, cause error) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE enrichment_jobs SET status = 'dead', locked_at = NULL, last_error = $2, updated_at = NOW() WHERE id = $1",
+		job.ID, cause.Error(),
+	)
+	return err
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-people-api/log"
+)
+
+// Purger хранилище, из которого можно окончательно удалить помеченные записи
+type Purger interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PurgeScheduler периодически удаляет людей, помеченных удалёнными дольше retention.
+// Запуск на нескольких экземплярах сервиса безопасен: DELETE идемпотентен.
+type PurgeScheduler struct {
+	store     Purger
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewPurgeScheduler(store Purger, retention, interval time.Duration) *PurgeScheduler {
+	return &PurgeScheduler{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start выполняет очистку сразу и затем каждые interval до вызова Stop
+func (s *PurgeScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.purge(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.WithContext(ctx).Infof("Purge of deleted people scheduled every %s, retention %s", s.interval, s.retention)
+}
+
+func (s *PurgeScheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *PurgeScheduler) purge(ctx context.Context) {
+	purged, err := s.store.Purge(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.WithContext(ctx).WithError(err).Error("Failed to purge deleted people")
+		}
+		return
+	}
+	if purged > 0 {
+		log.WithContext(ctx).Infof("Purged %d deleted people", purged)
+	}
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+	"go-people-api/repository"
+)
+
+func TestPurgeScheduler_RespectsRetention(t *testing.T) {
+	ctx := context.Background()
+	people := repository.NewMemoryPersonRepository()
+	for _, name := range []string{"Ivan", "Anna", "Olga"} {
+		_ = people.Create(ctx, &models.Person{Name: name, Surname: "Petrova"})
+	}
+	_ = people.Delete(ctx, 1, 0)
+	_ = people.Delete(ctx, 2, 0)
+
+	scheduler := NewPurgeScheduler(people, time.Hour, time.Minute)
+
+	scheduler.purge(ctx)
+	if _, err := people.Get(ctx, 1, true); err != nil {
+		t.Fatalf("recently deleted person must survive purge: %v", err)
+	}
+
+	scheduler.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	scheduler.purge(ctx)
+	for id := 1; id <= 2; id++ {
+		if _, err := people.Get(ctx, id, true); err != repository.ErrNotFound {
+			t.Errorf("person %d: expected purge, got %v", id, err)
+		}
+	}
+	if _, err := people.Get(ctx, 3, false); err != nil {
+		t.Errorf("active person must not be purged: %v", err)
+	}
+}
@@ -7,14 +7,17 @@ import (
 	"runtime"
 	"strings"
 
+	"go-people-api/requestid"
+
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var Logger *logrus.Logger
 
 type Config struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level" env:"LOG_LEVEL"`
+	Format string `mapstructure:"format" env:"LOG_FORMAT"`
 }
 
 func Init(config ...Config) {
@@ -26,10 +29,6 @@ func Init(config ...Config) {
 		if level, err := logrus.ParseLevel(config[0].Level); err == nil {
 			logLevel = level
 		}
-	} else if envLevel := os.Getenv("LOG_LEVEL"); envLevel != "" {
-		if level, err := logrus.ParseLevel(envLevel); err == nil {
-			logLevel = level
-		}
 	}
 	Logger.SetLevel(logLevel)
 
@@ -62,12 +61,16 @@ func WithContext(ctx context.Context) *logrus.Entry {
 	fields := logrus.Fields{}
 
 	if ctx != nil {
-		if requestID := ctx.Value("request_id"); requestID != nil {
+		if requestID := requestid.FromContext(ctx); requestID != "" {
 			fields["request_id"] = requestID
 		}
-		if userID := ctx.Value("user_id"); userID != nil {
+		if userID := requestid.UserIDFromContext(ctx); userID != "" {
 			fields["user_id"] = userID
 		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			fields["trace_id"] = span.TraceID().String()
+			fields["span_id"] = span.SpanID().String()
+		}
 	}
 
 	if pc, file, line, ok := runtime.Caller(1); ok {
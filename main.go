@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"time"
 
+	"go-people-api/auth"
+	"go-people-api/config"
 	"go-people-api/db"
 	"go-people-api/handlers"
+	"go-people-api/health"
+	"go-people-api/jobs"
 	"go-people-api/log"
+	"go-people-api/metrics"
+	"go-people-api/ratelimit"
+	"go-people-api/repository"
+	"go-people-api/requestid"
 	"go-people-api/services"
+	"go-people-api/tracing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -19,103 +31,414 @@ import (
 	_ "go-people-api/docs"
 )
 
+const usage = `usage: go-people-api [command] [arguments]
+
+commands:
+  serve     запустить HTTP-сервер (по умолчанию)
+  config    показать или проверить конфигурацию
+  migrate   применить или откатить миграции
+  person    создать, показать, перечислить или удалить людей
+  enrich    обогатить имя, не сохраняя результат
+  import    загрузить людей из CSV или NDJSON
+  export    выгрузить людей в CSV или NDJSON
+  apikey    выпустить, показать или отозвать ключи API
+
+Команда без аргументов показывает свои параметры.`
+
+// command подкоманда; к БД она подключается сама через connectDB, когда разобрала аргументы
+type command func(cfg *config.Config, args []string) int
+
+var commands = map[string]command{
+	"serve":   runServe,
+	"migrate": runMigrateCommand,
+	"person":  runPersonCommand,
+	"enrich":  runEnrichCommand,
+	"import":  runImportCommand,
+	"export":  runExportCommand,
+	"apikey":  runAPIKeyCommand,
+}
+
 func main() {
 	log.Init()
 
-	if err := loadEnvWithTimeout(5 * time.Second); err != nil {
-		log.Logger.Fatal("Failed to load environment variables: ", err)
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "config":
+		// Проверке конфигурации база не нужна
+		os.Exit(runConfigCommand(args))
+	case "help", "-h", "-help", "--help":
+		fmt.Println(usage)
+		return
+	}
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", name, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Logger.Fatalf("Invalid configuration:\n%v", err)
 	}
+	log.Init(cfg.Log)
+	if name != "serve" {
+		// В stdout команды пишут результат
+		log.Logger.SetOutput(os.Stderr)
+	}
+
+	os.Exit(run(cfg, args))
+}
 
-	if err := initDBWithRetry(5, 3*time.Second); err != nil {
+// connectDB подключает команду к БД; в отличие от сервера база не ждётся
+func connectDB(cfg *config.Config) error {
+	return initDBWithRetry(cfg.DB, 1, 0)
+}
+
+// runServe запускает HTTP-сервер и фоновые задачи и возвращается после остановки
+func runServe(cfg *config.Config, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: go-people-api serve")
+		return 2
+	}
+	if cfg.Server.GinMode != "" {
+		gin.SetMode(cfg.Server.GinMode)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing.Exporter)
+	if err != nil {
+		log.Logger.Fatal("Failed to initialize tracing: ", err)
+	}
+
+	if err := initDBWithRetry(cfg.DB, 5, 3*time.Second); err != nil {
 		log.Logger.Fatal("Failed to initialize database: ", err)
 	}
 	log.Logger.Info("Successfully connected to database")
+	metrics.RegisterDB(db.DB)
+
+	if cfg.DB.AutoMigrate {
+		if err := migrateOnStart(); err != nil {
+			log.Logger.Fatal("Failed to apply migrations: ", err)
+		}
+	}
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Logger.Fatal("Failed to configure authentication: ", err)
+	}
+
+	enrichmentService, err := setupEnrichment(cfg)
+	if err != nil {
+		log.Logger.Fatal("Failed to configure enrichment providers: ", err)
+	}
+	handlers.SetEnrichmentStatus(enrichmentService)
 
-	checkExternalAPIs()
+	personService, backgroundEnricher := withEnrichmentCache(cfg.Enrichment, enrichmentService)
+	handlers.SetPersonService(personService)
 
-	enrichmentService := services.NewEnrichmentService(
-		os.Getenv("AGE_API"),
-		os.Getenv("GENDER_API"),
-		os.Getenv("NATIONALITY_API"),
+	personRepository := repository.NewPostgresPersonRepository(db.DB)
+	handlers.SetPersonRepository(personRepository)
+
+	// Этапы остановки после HTTP-сервера: сначала фоновые задачи, которые ещё
+	// обращаются к провайдерам и БД, затем клиенты провайдеров, пул БД и трассы
+	var steps []shutdownStep
+	if worker := startEnrichmentWorker(cfg, backgroundEnricher, personRepository); worker != nil {
+		steps = append(steps, shutdownStep{name: "enrichment worker", fn: waitStop(worker.Stop)})
+	}
+	if purger := startPurgeScheduler(cfg.People, personRepository); purger != nil {
+		steps = append(steps, shutdownStep{name: "purge scheduler", fn: waitStop(purger.Stop)})
+	}
+	steps = append(steps,
+		shutdownStep{name: "enrichment clients", fn: waitStop(enrichmentService.Close)},
+		shutdownStep{name: "database", fn: func(context.Context) error { return db.Close() }},
+		shutdownStep{name: "tracing", fn: shutdownTracing},
 	)
-	handlers.SetPersonService(enrichmentService)
 
-	r := setupRouter()
+	checker := newHealthChecker(cfg.Readiness, enrichmentService)
+	r := setupRouter(cfg.Server, cfg.RateLimit, authenticator, newRateLimiter(cfg.RateLimit.Store), checker)
+
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Server.Port),
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Logger.Infof("Server starting on port %d", cfg.Server.Port)
+	serve(srv, cfg.Server, checker, steps)
+	return 0
+}
+
+// initDBWithRetry подключается к БД, делая до maxRetries попыток с паузой delay между ними
+func initDBWithRetry(config db.Config, maxRetries int, delay time.Duration) error {
+	var lastErr error
+	for i := 1; i <= maxRetries; i++ {
+		if lastErr = db.Init(config); lastErr == nil {
+			return nil
+		}
+		if i < maxRetries {
+			log.Logger.Warnf("Database connection attempt %d/%d failed, retrying in %s: %v", i, maxRetries, delay, lastErr)
+			time.Sleep(delay)
+		}
+	}
+	return fmt.Errorf("after %d attempts, last error: %w", maxRetries, lastErr)
+}
+
+// setupEnrichment создаёт сервис обогащения с политиками и порогами квот из конфигурации
+func setupEnrichment(cfg *config.Config) (*services.EnrichmentService, error) {
+	service, err := newEnrichmentService(cfg.Enrichment)
+	if err != nil {
+		return nil, err
+	}
+	configureProviderPolicies(cfg, service)
+	service.SetQuotaThresholds(cfg.Enrichment.QuotaWarnThresholds)
+	return service, nil
+}
+
+// newEnrichmentService собирает провайдеров из ENRICHMENT_PROVIDERS,
+// а если переменная не задана — из AGE_API/GENDER_API/NATIONALITY_API
+func newEnrichmentService(config config.EnrichmentConfig) (*services.EnrichmentService, error) {
+	if config.Providers == "" {
+		checkExternalAPIs(config)
+		return services.NewEnrichmentService(config.AgeAPI, config.GenderAPI, config.NationalityAPI), nil
+	}
+
+	kinds, err := services.ParseProviderKinds(config.ProviderKinds)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := services.ParseProviders(config.Providers, kinds)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		log.Logger.Infof("Enrichment provider %s fills %v", p.Name(), p.Fields())
+	}
+	return services.NewEnrichmentServiceWithProviders(providers...), nil
+}
+
+// configureProviderPolicies задаёт политики повторов и автомата: ENRICHMENT_RETRY_ATTEMPTS
+// для всех провайдеров, ENRICHMENT_<NAME>_RETRY_ATTEMPTS — для конкретного
+// (аналогично для остальных параметров)
+func configureProviderPolicies(cfg *config.Config, service *services.EnrichmentService) {
+	for _, p := range service.Providers() {
+		if err := service.SetProviderPolicy(p.Name(), cfg.ProviderPolicy(p.Name())); err != nil {
+			log.Logger.Warn("Failed to set provider policy: ", err)
+		}
+	}
+}
+
+// withEnrichmentCache оборачивает сервис обогащения кэшем, если он не отключён
+// (ENRICHMENT_CACHE_SIZE=0). Второй результат — обогащение для фоновых задач,
+// которое не отдаёт закэшированные ошибки.
+func withEnrichmentCache(config config.EnrichmentConfig, service services.Enricher) (handlers.PersonService, services.Enricher) {
+	if config.CacheSize <= 0 {
+		log.Logger.Info("Enrichment cache disabled")
+		return service, service
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8086"
+	stores := []services.CacheStore{services.NewMemoryCacheStore(config.CacheSize)}
+	if config.CachePersistent {
+		stores = append(stores, services.NewPostgresCacheStore(db.DB))
 	}
 
-	log.Logger.Info("Server starting on port " + port)
-	if err := r.Run(":" + port); err != nil {
-		log.Logger.Fatal("Server failed: ", err)
+	cache := services.NewCachingEnricher(service, config.CacheTTL, config.CacheNegativeTTL, stores...)
+	handlers.SetEnrichmentCache(cache)
+	metrics.RegisterCache(cache.Stats)
+	return cache, cache.RetryEnricher()
+}
+
+// startEnrichmentWorker запускает фоновое обогащение, если ENRICHMENT_WORKERS > 0
+func startEnrichmentWorker(cfg *config.Config, enricher services.Enricher, people *repository.PostgresPersonRepository) *jobs.EnrichmentWorker {
+	workerConfig := cfg.Jobs.WorkerConfig()
+	if workerConfig.Workers <= 0 {
+		return nil
 	}
+
+	store := jobs.NewPostgresJobStore(db.DB, cfg.Jobs.MaxAttempts, 2*workerConfig.JobTimeout)
+	handlers.SetEnrichmentQueue(jobs.NewPostgresEnrichmentQueue(people, store), cfg.Enrichment.Async)
+
+	worker := jobs.NewEnrichmentWorker(store, enricher, people, workerConfig)
+	worker.Start(context.Background())
+	return worker
 }
 
-func loadEnvWithTimeout(timeout time.Duration) error {
-	errChan := make(chan error)
-	go func() {
-		if err := godotenv.Load(); err != nil {
-			errChan <- fmt.Errorf("failed to load .env file: %w", err)
-			return
+// newAuthenticator настраивает проверку ключей API и JWT (AUTH_JWT_*).
+// AUTH_ENABLED=false отключает аутентификацию — только для локальной разработки.
+func newAuthenticator(config config.AuthConfig) (*auth.Authenticator, error) {
+	keys := auth.NewAPIKeys(auth.NewPostgresKeyStore(db.DB))
+	handlers.SetAPIKeyManager(keys)
+
+	if !config.Enabled {
+		log.Logger.Warn("Authentication disabled, all routes are anonymous")
+		return nil, nil
+	}
+
+	jwtConfig := auth.JWTConfig{
+		HMACSecret: []byte(config.JWTHS256Secret),
+		Issuer:     config.JWTIssuer,
+		Audience:   config.JWTAudience,
+		Leeway:     config.JWTLeeway,
+	}
+	if path := config.JWTRS256PublicKeyFile; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RS256 public key: %w", err)
 		}
-		errChan <- nil
-	}()
+		if jwtConfig.RSAPublicKey, err = auth.ParseRSAPublicKey(pem); err != nil {
+			return nil, fmt.Errorf("invalid RS256 public key: %w", err)
+		}
+	}
+
+	if len(jwtConfig.HMACSecret) == 0 && jwtConfig.RSAPublicKey == nil {
+		log.Logger.Info("JWT authentication not configured, only API keys are accepted")
+		return auth.NewAuthenticator(keys, nil), nil
+	}
 
-	select {
-	case err := <-errChan:
-		return err
-	case <-time.After(timeout):
-		return fmt.Errorf("timeout while loading .env file")
+	verifier, err := auth.NewJWTVerifier(jwtConfig)
+	if err != nil {
+		return nil, err
 	}
+	return auth.NewAuthenticator(keys, verifier), nil
 }
 
-func initDBWithRetry(maxRetries int, delay time.Duration) error {
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		if err := db.Init(); err == nil {
-			return nil
+// newRateLimiter выбирает хранилище лимитов: memory (по умолчанию) или postgres,
+// общее для всех экземпляров; RATE_LIMIT_STORE=off отключает ограничения
+func newRateLimiter(store string) *ratelimit.Limiter {
+	switch store {
+	case "off":
+		log.Logger.Warn("Rate limiting disabled")
+		return nil
+	case "postgres":
+		return ratelimit.NewLimiter(ratelimit.NewPostgresStore(db.DB))
+	default:
+		return ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	}
+}
+
+// newHealthChecker настраивает проверки /readyz: пинг БД (критичная) и, при
+// READINESS_CHECK_PROVIDERS=true, доступность хостов провайдеров обогащения (некритичные)
+func newHealthChecker(config config.ReadinessConfig, enrichment *services.EnrichmentService) *health.Checker {
+	checker := health.NewChecker()
+	checker.Add("database", config.DBTimeout, true, func(ctx context.Context) error {
+		conn, err := db.GetDB()
+		if err != nil {
+			return err
 		}
+		return conn.PingContext(ctx)
+	})
 
+	if !config.CheckProviders {
+		return checker
 	}
-	return fmt.Errorf("after %d attempts, last error: %w", maxRetries, lastErr)
+	for _, p := range enrichment.Providers() {
+		// Адрес для проверки берётся из шаблона запроса; имя не важно, запрос не отправляется
+		if target := p.RequestURL("healthcheck"); target != "" {
+			checker.Add("enrichment."+p.Name(), config.ProviderTimeout, false, health.DialCheck(target))
+		}
+	}
+	return checker
+}
+
+// startPurgeScheduler запускает окончательное удаление людей, помеченных удалёнными
+// дольше PEOPLE_RETENTION; 0 отключает очистку
+func startPurgeScheduler(config config.PeopleConfig, people repository.PersonRepository) *jobs.PurgeScheduler {
+	if config.Retention <= 0 {
+		return nil
+	}
+
+	purger := jobs.NewPurgeScheduler(people, config.Retention, config.PurgeInterval)
+	purger.Start(context.Background())
+	return purger
 }
 
-func checkExternalAPIs() {
-	requiredAPIs := map[string]string{
-		"AGE_API":         "Age API",
-		"GENDER_API":      "Gender API",
-		"NATIONALITY_API": "Nationality API",
+func checkExternalAPIs(config config.EnrichmentConfig) {
+	requiredAPIs := []struct {
+		env, name, url string
+	}{
+		{"AGE_API", "Age API", config.AgeAPI},
+		{"GENDER_API", "Gender API", config.GenderAPI},
+		{"NATIONALITY_API", "Nationality API", config.NationalityAPI},
 	}
 
-	for env, name := range requiredAPIs {
-		if os.Getenv(env) == "" {
-			log.Logger.Warnf("%s endpoint not configured (%s environment variable is empty)", name, env)
+	for _, api := range requiredAPIs {
+		if api.url == "" {
+			log.Logger.Warnf("%s endpoint not configured (%s is empty)", api.name, api.env)
 		} else {
-			log.Logger.Infof("%s endpoint: %s", name, os.Getenv(env))
+			log.Logger.Infof("%s endpoint: %s", api.name, api.url)
 		}
 	}
 }
 
-func setupRouter() *gin.Engine {
+// setupRouter регистрирует маршруты; при authenticator == nil API доступен без аутентификации,
+// при limiter == nil — без ограничения частоты запросов
+func setupRouter(server config.ServerConfig, limits config.RateLimitConfig, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, checker *health.Checker) *gin.Engine {
 	r := gin.New()
+	// По умолчанию gin доверяет X-Forwarded-For от любого адреса, и клиент мог бы
+	// сам выбирать себе ведро лимита по IP. Адреса проверены в config.Validate.
+	if err := r.SetTrustedProxies(server.TrustedProxies); err != nil {
+		log.Logger.WithError(err).Error("Invalid trusted proxies, X-Forwarded-For is ignored")
+		_ = r.SetTrustedProxies(nil)
+	}
+	// Идентификатор запроса ставится первым, чтобы попасть в заголовок даже при панике
+	r.Use(requestid.Middleware())
 	r.Use(gin.Recovery())
+	r.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(req *http.Request) bool {
+		return req.URL.Path != "/metrics" && req.URL.Path != "/healthz" && req.URL.Path != "/readyz"
+	})))
+	r.Use(metrics.Middleware())
 
-	if os.Getenv("GIN_MODE") != "release" {
+	if gin.Mode() != gin.ReleaseMode {
 		r.Use(gin.Logger())
 	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/metrics", metrics.Handler())
+	// Пробы оркестратора доступны без аутентификации и лимитов
+	r.GET("/healthz", health.Liveness())
+	r.GET("/readyz", checker.Readiness())
 
 	api := r.Group("/api/v1")
+	// Лимит по IP стоит до аутентификации: перебор ключей и токенов не должен быть бесплатным
+	api.Use(limiter.IPMiddleware("ip", limits.IP))
+	if authenticator != nil {
+		api.Use(auth.Middleware(authenticator))
+	}
+	// Остальные лимиты считаются после аутентификации, чтобы вёдра были по ключам, а не по IP.
+	// POST /people и пакетные операции дополнительно ограничены: они расходуют квоту внешних API.
+	api.Use(limiter.Middleware("api", limits.Default))
+	create := limiter.Middleware("create", limits.Create)
+	bulk := limiter.Middleware("bulk", limits.Bulk)
+
+	read := auth.RequireScopes(auth.ScopePeopleRead)
+	write := auth.RequireScopes(auth.ScopePeopleWrite)
+	remove := auth.RequireScopes(auth.ScopePeopleDelete)
+	{
+		api.POST("/people", write, create, handlers.CreatePerson)
+		api.POST("/people/batch", write, bulk, handlers.CreatePeopleBatch)
+		api.POST("/people/import", write, bulk, handlers.ImportPeople)
+		api.GET("/people/export", read, bulk, handlers.ExportPeople)
+		api.GET("/people", read, handlers.GetPeople)
+		api.GET("/people/:id", read, handlers.GetPersonByID)
+		api.PUT("/people/:id", write, handlers.UpdatePerson)
+		api.PATCH("/people/:id", write, handlers.PatchPerson)
+		api.DELETE("/people/:id", remove, handlers.DeletePerson)
+		api.POST("/people/:id/restore", write, handlers.RestorePerson)
+		api.GET("/people/:id/history", read, handlers.GetPersonHistory)
+		api.POST("/people/:id/revert", write, handlers.RevertPerson)
+	}
+
+	admin := api.Group("/admin", auth.RequireScopes(auth.ScopeAdmin))
 	{
-		api.POST("/people", handlers.CreatePerson)
-		api.GET("/people", handlers.GetPeople)
-		api.GET("/people/:id", handlers.GetPersonByID)
-		api.PUT("/people/:id", handlers.UpdatePerson)
-		api.PATCH("/people/:id", handlers.PatchPerson)
-		api.DELETE("/people/:id", handlers.DeletePerson)
+		admin.GET("/enrichment/providers", handlers.GetEnrichmentProviders)
+		admin.GET("/enrichment/quotas", handlers.GetEnrichmentQuotas)
+		admin.GET("/enrichment/cache", handlers.GetEnrichmentCacheStats)
+		admin.DELETE("/enrichment/cache", handlers.PurgeEnrichmentCache)
+		admin.DELETE("/enrichment/cache/:name", handlers.PurgeEnrichmentCache)
+		admin.POST("/apikeys", handlers.IssueAPIKey)
+		admin.GET("/apikeys", handlers.ListAPIKeys)
+		admin.DELETE("/apikeys/:id", handlers.RevokeAPIKey)
 	}
 
 	return r
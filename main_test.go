@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/handlers"
+	"go-people-api/health"
+	"go-people-api/ratelimit"
+	"go-people-api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetupRouter_IPLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handlers.SetPersonRepository(repository.NewMemoryPersonRepository())
+	limits := config.RateLimitConfig{IP: ratelimit.Limit{Requests: 1, Period: time.Minute}}
+
+	do := func(r *gin.Engine, remote, forwarded string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/people", nil)
+		req.RemoteAddr = remote + ":40000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Без TRUSTED_PROXIES новый X-Forwarded-For не даёт нового ведра
+	r := setupRouter(config.ServerConfig{}, limits, nil, ratelimit.NewLimiter(ratelimit.NewMemoryStore()), health.NewChecker())
+	if code := do(r, "192.0.2.1", "10.0.0.1"); code != http.StatusOK {
+		t.Fatalf("first request: expected 200, got %d", code)
+	}
+	if code := do(r, "192.0.2.1", "10.0.0.2"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For: expected 429, got %d", code)
+	}
+
+	// За доверенным прокси клиенты различаются по X-Forwarded-For
+	server := config.ServerConfig{TrustedProxies: []string{"192.0.2.0/24"}}
+	r = setupRouter(server, limits, nil, ratelimit.NewLimiter(ratelimit.NewMemoryStore()), health.NewChecker())
+	if code := do(r, "192.0.2.1", "10.0.0.1"); code != http.StatusOK {
+		t.Fatalf("first client: expected 200, got %d", code)
+	}
+	if code := do(r, "192.0.2.1", "10.0.0.2"); code != http.StatusOK {
+		t.Errorf("second client behind proxy: expected 200, got %d", code)
+	}
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "people"
+
+// Результаты обращения к провайдеру обогащения
+const (
+	OutcomeSuccess        = "success"
+	OutcomeError          = "error"
+	OutcomeRateLimited    = "rate_limited"
+	OutcomeCircuitOpen    = "circuit_open"
+	OutcomeQuotaExhausted = "quota_exhausted"
+)
+
+// Registry собственный реестр сервиса; глобальный реестр prometheus не используется,
+// чтобы тесты и повторная инициализация не падали на повторной регистрации
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	enrichmentRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_requests_total",
+		Help:      "Enrichment provider calls by outcome, including calls skipped by the breaker or quota.",
+	}, []string{"provider", "outcome"})
+
+	enrichmentDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrichment_request_duration_seconds",
+		Help:      "Latency of HTTP calls to enrichment providers.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 0.8, 1, 2, 3},
+	}, []string{"provider"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		enrichmentRequests,
+		enrichmentDuration,
+	)
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware считает запросы и их длительность. Маршрут берётся из шаблона (/people/:id),
+// а не из пути, чтобы число рядов не зависело от id.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveEnrichment учитывает обращение к провайдеру; duration 0 — запрос не отправлялся
+func ObserveEnrichment(provider, outcome string, duration time.Duration) {
+	enrichmentRequests.WithLabelValues(provider, outcome).Inc()
+	if duration > 0 {
+		enrichmentDuration.WithLabelValues(provider).Observe(duration.Seconds())
+	}
+}
+
+// RegisterDB добавляет статистику пула соединений (db.DB.Stats())
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterCache добавляет метрики кэша обогащения; значения читаются при каждом сборе
+func RegisterCache(stats func() models.CacheStats) {
+	Registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enrichment_cache_hits_total",
+			Help:      "Enrichment cache hits with a successful result.",
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enrichment_cache_negative_hits_total",
+			Help:      "Enrichment cache hits with a cached error.",
+		}, func() float64 { return float64(stats().NegativeHits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enrichment_cache_misses_total",
+			Help:      "Enrichment cache misses.",
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "enrichment_cache_hit_ratio",
+			Help:      "Share of enrichment lookups served from the cache.",
+		}, func() float64 { return stats().HitRatio }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "enrichment_cache_entries",
+			Help:      "Entries in the in-memory enrichment cache.",
+		}, func() float64 { return float64(stats().Entries) }),
+	)
+}
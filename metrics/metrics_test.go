@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/metrics", Handler())
+	r.GET("/people/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/people/1", "/people/2", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	ObserveEnrichment("age", OutcomeSuccess, 120*time.Millisecond)
+	ObserveEnrichment("age", OutcomeQuotaExhausted, 0)
+	RegisterCache(func() models.CacheStats {
+		return models.CacheStats{Hits: 3, Misses: 1, HitRatio: 0.75, Entries: 2}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`people_http_requests_total{method="GET",route="/people/:id",status="404"} 2`,
+		`people_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`people_http_request_duration_seconds_count{method="GET",route="/people/:id"} 2`,
+		`people_enrichment_requests_total{outcome="success",provider="age"} 1`,
+		`people_enrichment_requests_total{outcome="quota_exhausted",provider="age"} 1`,
+		`people_enrichment_request_duration_seconds_count{provider="age"} 1`,
+		`people_enrichment_cache_hit_ratio 0.75`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
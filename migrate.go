@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/db"
+	"go-people-api/log"
+)
+
+const migrateUsage = `usage:
+  go-people-api migrate up
+  go-people-api migrate down [-steps 1]
+  go-people-api migrate goto -version <version>   (0 — откатить все)
+  go-people-api migrate status`
+
+// runMigrateCommand применяет встроенные миграции из командной строки. Возвращает код завершения.
+func runMigrateCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err := connectDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "database:", err)
+		return 1
+	}
+
+	migrator, err := db.NewMigrator(db.DB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	// Ожидание блокировки другой репликой тоже входит в таймаут
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	var applied []db.Migration
+	switch args[0] {
+	case "up":
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		applied, err = migrator.Up(ctx)
+
+	case "down":
+		steps := flags.Int("steps", 1, "сколько миграций откатить")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		applied, err = migrator.Down(ctx, *steps)
+
+	case "goto":
+		version := flags.Int64("version", -1, "целевая версия")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if *version < 0 {
+			fmt.Fprintln(os.Stderr, "migrate goto: -version is required")
+			return 2
+		}
+		applied, err = migrator.Goto(ctx, *version)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate status:", err)
+			return 1
+		}
+		printMigrationStatus(status)
+		return 0
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	for _, m := range applied {
+		fmt.Printf("%s %d_%s\n", args[0], m.Version, m.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", args[0], err)
+		return 1
+	}
+	if len(applied) == 0 {
+		fmt.Println("no change")
+	}
+	return 0
+}
+
+func printMigrationStatus(status db.MigrationStatus) {
+	fmt.Printf("version: %d", status.Version)
+	if status.Dirty {
+		fmt.Print(" (dirty)")
+	}
+	fmt.Println()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, m := range status.Migrations {
+		state := "pending"
+		if m.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", m.Version, m.Name, state)
+	}
+	_ = tw.Flush()
+}
+
+// migrateOnStart применяет новые миграции перед запуском сервера (DB_AUTO_MIGRATE=true);
+// реплики, стартующие одновременно, ждут друг друга на advisory-блокировке
+func migrateOnStart() error {
+	migrator, err := db.NewMigrator(db.DB)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		log.Logger.Infof("Applied migration %d_%s", m.Version, m.Name)
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// APIKey ключ доступа к API; сам ключ не хранится, только его SHA-256
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssueAPIKeyRequest запрос на выпуск ключа; ExpiresIn — длительность вида "720h", пустая — бессрочный ключ.
+// Права задаются ролями и/или списком scopes; без них ключ получает роль reader.
+type IssueAPIKeyRequest struct {
+	UserID    string   `json:"user_id" binding:"required,max=255"`
+	Name      string   `json:"name" binding:"max=255"`
+	Roles     []string `json:"roles"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn string   `json:"expires_in"`
+}
+
+// IssuedAPIKey выпущенный ключ; Key возвращается только один раз
+type IssuedAPIKey struct {
+	Key string `json:"key"`
+	APIKey
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CacheStats статистика кэша обогащения
+type CacheStats struct {
+	Hits         int64   `json:"hits"`
+	NegativeHits int64   `json:"negative_hits"`
+	Misses       int64   `json:"misses"`
+	HitRatio     float64 `json:"hit_ratio"`
+	Entries      int     `json:"entries"`
+}
+
+// ProviderStatus состояние провайдера обогащения
+type ProviderStatus struct {
+	Name                string     `json:"name"`
+	Fields              []string   `json:"fields"`
+	Configured          bool       `json:"configured"`
+	Breaker             string     `json:"breaker"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// QuotaStatus квота провайдера по последнему ответу; поля пустые, пока провайдер не вернул заголовки квоты
+type QuotaStatus struct {
+	Provider  string     `json:"provider"`
+	Limit     int        `json:"limit,omitempty"`
+	Remaining *int       `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+	Exhausted bool       `json:"exhausted"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
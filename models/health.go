@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Статусы проверок готовности
+const (
+	HealthOK           = "ok"
+	HealthDegraded     = "degraded"
+	HealthFail         = "fail"
+	HealthShuttingDown = "shutting_down"
+)
+
+// HealthCheck результат проверки одной зависимости
+type HealthCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport ответ /healthz и /readyz
+type HealthReport struct {
+	Status    string        `json:"status"`
+	Checks    []HealthCheck `json:"checks,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
@@ -15,6 +15,56 @@ type Person struct {
 	Nationality string    `json:"nationality,omitempty" binding:"omitempty,len=2" db:"nationality"`
 	CreatedAt   time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	EnrichmentStatus string     `json:"enrichment_status,omitempty" db:"enrichment_status"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Attributes дополнительные атрибуты от провайдеров обогащения (ethnicity, locale, ...)
+	Attributes map[string]string `json:"attributes,omitempty" db:"attributes"`
+	// Version увеличивается при каждом изменении и используется как ETag
+	Version int `json:"version,omitempty" db:"version"`
+}
+
+// Статусы обогащения человека
+const (
+	EnrichmentPending   = "pending"
+	EnrichmentCompleted = "completed"
+	EnrichmentFailed    = "failed"
+)
+
+// MergeEnrichment дополняет введённые данные результатом обогащения;
+// заданные пользователем поля не перезаписываются
+func MergeEnrichment(input, enriched *Person) *Person {
+	if enriched == nil {
+		return input
+	}
+
+	result := *input
+	if enriched.Age > 0 && input.Age == 0 {
+		result.Age = enriched.Age
+	}
+	if enriched.Gender != "" && input.Gender == "" {
+		result.Gender = enriched.Gender
+	}
+	if enriched.Nationality != "" && input.Nationality == "" {
+		result.Nationality = enriched.Nationality
+	}
+	result.Attributes = MergeAttributes(input.Attributes, enriched.Attributes)
+	return &result
+}
+
+// MergeAttributes возвращает новую карту: атрибуты current дополняются отсутствующими из enriched
+func MergeAttributes(current, enriched map[string]string) map[string]string {
+	if len(enriched) == 0 {
+		return current
+	}
+	merged := make(map[string]string, len(current)+len(enriched))
+	for key, value := range enriched {
+		merged[key] = value
+	}
+	for key, value := range current {
+		merged[key] = value
+	}
+	return merged
 }
 
 // PersonFilter содержит параметры фильтрации для поиска людей
@@ -25,6 +75,24 @@ type PersonFilter struct {
 	AgeFrom     *int   `json:"age_from,omitempty" form:"age_from"`
 	AgeTo       *int   `json:"age_to,omitempty" form:"age_to"`
 	Nationality string `json:"nationality,omitempty" form:"nationality"`
+	// IncludeDeleted включает в выборку удалённых (soft delete) людей
+	IncludeDeleted bool `json:"include_deleted,omitempty" form:"include_deleted"`
+
+	Limit  int    `json:"limit,omitempty" form:"limit" binding:"omitempty,min=1,max=1000"`
+	Offset int    `json:"offset,omitempty" form:"offset" binding:"omitempty,min=0"`
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+	Sort   string `json:"sort,omitempty" form:"sort"`
+}
+
+// PeoplePage страница результатов списка людей
+type PeoplePage struct {
+	Data       []Person `json:"data"`
+	Total      int      `json:"total"`
+	Limit      int      `json:"limit"`
+	Offset     int      `json:"offset,omitempty"`
+	Sort       string   `json:"sort"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	PrevCursor string   `json:"prev_cursor,omitempty"`
 }
 
 // UpdatePersonRequest содержит поля для частичного обновления
@@ -35,6 +103,26 @@ type UpdatePersonRequest struct {
 	Gender      *string `json:"gender,omitempty" binding:"omitempty,oneof=male female other"`
 	Age         *int    `json:"age,omitempty" binding:"omitempty,min=1,max=120"`
 	Nationality *string `json:"nationality,omitempty" binding:"omitempty,len=2"`
+
+	// Null поля, которые нужно очистить (merge patch null, JSON Patch remove)
+	Null []string `json:"-"`
+	// Tests условия операций JSON Patch "test", проверяемые в момент изменения
+	Tests []FieldTest `json:"-"`
+}
+
+// NullableFields поля человека, которые можно очистить
+var NullableFields = []string{"patronymic", "gender", "age", "nationality"}
+
+// FieldTest требует, чтобы поле имело значение Value (nil — поле пустое)
+type FieldTest struct {
+	Field string
+	Value interface{}
+}
+
+// IsEmpty сообщает, что запрос ничего не меняет
+func (r UpdatePersonRequest) IsEmpty() bool {
+	return r.Name == nil && r.Surname == nil && r.Patronymic == nil && r.Gender == nil &&
+		r.Age == nil && r.Nationality == nil && len(r.Null) == 0
 }
 
 // ErrorResponse стандартный формат ответа об ошибке
@@ -42,4 +130,81 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// RequestID идентификатор запроса (X-Request-ID) для поиска в логах
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// BatchItemResult результат создания одной записи пакета
+type BatchItemResult struct {
+	Index  int            `json:"index"`
+	Status string         `json:"status"`
+	Person *Person        `json:"person,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchResponse ответ на пакетное создание людей
+type BatchResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// ImportRowError ошибка в строке файла импорта
+type ImportRowError struct {
+	Line  int           `json:"line"`
+	Error ErrorResponse `json:"error"`
+}
+
+// ImportReport результат импорта; при dry_run записи только проверяются
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// Действия, записываемые в историю изменений
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryPatch   = "patch"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	HistoryRevert  = "revert"
+	HistoryEnrich  = "enrich"
+	// HistoryPurge окончательное удаление; записи истории человека при этом сохраняются
+	HistoryPurge = "purge"
+)
+
+// PersonHistoryEntry одно изменение человека: состояние до и после, изменённые поля и источник
+type PersonHistoryEntry struct {
+	ID            int64     `json:"id"`
+	PersonID      int       `json:"person_id"`
+	Action        string    `json:"action"`
+	Before        *Person   `json:"before,omitempty"`
+	After         *Person   `json:"after,omitempty"`
+	ChangedFields []string  `json:"changed_fields"`
+	RequestID     string    `json:"request_id,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// HistoryPage страница истории изменений, от новых к старым
+type HistoryPage struct {
+	Data   []PersonHistoryEntry `json:"data"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset,omitempty"`
+}
+
+// HistoryFilter параметры просмотра истории
+type HistoryFilter struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// RevertRequest возврат человека к состоянию после указанной записи истории
+type RevertRequest struct {
+	HistoryID int64 `json:"history_id" binding:"required,min=1"`
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/db"
+	"go-people-api/models"
+	"go-people-api/repository"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+const personUsage = `usage:
+  go-people-api person create -name <name> -surname <surname> [-patronymic <p>] [-gender male|female|other] [-age N] [-nationality XX] [-no-enrich]
+  go-people-api person get -id <id> [-include-deleted]
+  go-people-api person list [-name <s>] [-surname <s>] [-gender <g>] [-nationality XX] [-age-from N] [-age-to N] [-include-deleted] [-limit N] [-cursor <c>] [-sort <field>]
+  go-people-api person delete -id <id> [-if-version N]`
+
+// runPersonCommand работает с людьми так же, как API: создание с обогащением,
+// просмотр, список с фильтрами и удаление (soft delete). Возвращает код завершения.
+func runPersonCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, personUsage)
+		return 2
+	}
+	if err := connectDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "database:", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	people := repository.NewPostgresPersonRepository(db.DB)
+	flags := flag.NewFlagSet("person "+args[0], flag.ContinueOnError)
+
+	var result interface{}
+	switch args[0] {
+	case "create":
+		var input models.Person
+		flags.StringVar(&input.Name, "name", "", "имя")
+		flags.StringVar(&input.Surname, "surname", "", "фамилия")
+		flags.StringVar(&input.Patronymic, "patronymic", "", "отчество")
+		flags.StringVar(&input.Gender, "gender", "", "пол; иначе определяется обогащением")
+		flags.IntVar(&input.Age, "age", 0, "возраст; иначе определяется обогащением")
+		flags.StringVar(&input.Nationality, "nationality", "", "код страны; иначе определяется обогащением")
+		noEnrich := flags.Bool("no-enrich", false, "сохранить без обращения к провайдерам")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := validatePerson(&input); err != nil {
+			fmt.Fprintln(os.Stderr, "person create:", err)
+			return 2
+		}
+
+		person, err := createPerson(ctx, cfg, people, &input, !*noEnrich)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "person create:", err)
+			return 1
+		}
+		result = person
+
+	case "get":
+		id := flags.Int("id", 0, "id человека")
+		includeDeleted := flags.Bool("include-deleted", false, "искать и среди удалённых")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		person, err := people.Get(ctx, *id, *includeDeleted)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "person get:", err)
+			return 1
+		}
+		result = person
+
+	case "list":
+		var filter models.PersonFilter
+		flags.StringVar(&filter.Name, "name", "", "имя (подстрока)")
+		flags.StringVar(&filter.Surname, "surname", "", "фамилия (подстрока)")
+		flags.StringVar(&filter.Gender, "gender", "", "пол")
+		flags.StringVar(&filter.Nationality, "nationality", "", "код страны")
+		flags.Func("age-from", "минимальный возраст", intPointer(&filter.AgeFrom))
+		flags.Func("age-to", "максимальный возраст", intPointer(&filter.AgeTo))
+		flags.BoolVar(&filter.IncludeDeleted, "include-deleted", false, "включить удалённых")
+		flags.IntVar(&filter.Limit, "limit", 0, "размер страницы (1-1000)")
+		flags.StringVar(&filter.Cursor, "cursor", "", "курсор next_cursor/prev_cursor предыдущей страницы")
+		flags.StringVar(&filter.Sort, "sort", "", "поле сортировки, -поле — по убыванию")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := binding.Validator.ValidateStruct(&filter); err != nil {
+			fmt.Fprintln(os.Stderr, "person list:", err)
+			return 2
+		}
+		page, err := people.List(ctx, filter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "person list:", err)
+			if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+				return 2
+			}
+			return 1
+		}
+		result = page
+
+	case "delete":
+		id := flags.Int("id", 0, "id человека")
+		ifVersion := flags.Int("if-version", 0, "удалить, только если версия совпадает")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := people.Delete(ctx, *id, *ifVersion); err != nil {
+			fmt.Fprintln(os.Stderr, "person delete:", err)
+			return 1
+		}
+		result = map[string]interface{}{"status": "deleted", "id": *id}
+
+	default:
+		fmt.Fprintln(os.Stderr, personUsage)
+		return 2
+	}
+
+	return printJSON(result)
+}
+
+// createPerson обогащает и сохраняет человека, как POST /api/v1/people без async
+func createPerson(ctx context.Context, cfg *config.Config, people repository.PersonRepository, input *models.Person, enrich bool) (*models.Person, error) {
+	if !enrich {
+		return input, people.Create(ctx, input)
+	}
+
+	service, err := setupEnrichment(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer service.Close()
+
+	enriched, err := service.Enrich(ctx, input.Name)
+	person := models.MergeEnrichment(input, enriched)
+	person.EnrichmentStatus = models.EnrichmentCompleted
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+		person.EnrichmentStatus = models.EnrichmentFailed
+	}
+	return person, people.Create(ctx, person)
+}
+
+// validatePerson проверяет человека по тем же правилам, что и API
+func validatePerson(person *models.Person) error {
+	if person.Name == "" || person.Surname == "" {
+		return errors.New("name and surname are required")
+	}
+	return binding.Validator.ValidateStruct(person)
+}
+
+// intPointer разбирает необязательный числовой флаг в *int
+func intPointer(target **int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*target = &n
+		return nil
+	}
+}
+
+func printJSON(v interface{}) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit ведро на Requests запросов, которое полностью наполняется за Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit разбирает лимит вида "60/m", "10/s", "1000/h" или "30/10s".
+// Пустая строка и "0" означают отсутствие лимита (нулевой Limit).
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Limit{}, nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>", value)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad request count", value)
+	}
+
+	var d time.Duration
+	switch period {
+	case "s":
+		d = time.Second
+	case "m":
+		d = time.Minute
+	case "h":
+		d = time.Hour
+	default:
+		if d, err = time.ParseDuration(period); err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: bad period", value)
+		}
+	}
+	return Limit{Requests: requests, Period: d}, nil
+}
+
+// Enabled сообщает, задан ли лимит
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// UnmarshalText позволяет задавать лимит строкой в конфигурации
+func (l *Limit) UnmarshalText(text []byte) error {
+	parsed, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// MarshalText возвращает лимит в виде, который принимает ParseLimit; "0" — без лимита
+func (l Limit) MarshalText() ([]byte, error) {
+	if !l.Enabled() {
+		return []byte("0"), nil
+	}
+	period := l.Period.String()
+	switch l.Period {
+	case time.Second:
+		period = "s"
+	case time.Minute:
+		period = "m"
+	case time.Hour:
+		period = "h"
+	}
+	return []byte(strconv.Itoa(l.Requests) + "/" + period), nil
+}
+
+// rate токенов в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result итог попытки взять токен
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset время до полного наполнения ведра
+	Reset time.Duration
+	// RetryAfter время до появления следующего токена; 0, если запрос разрешён
+	RetryAfter time.Duration
+}
+
+// Store хранилище вёдер; now — время запроса по часам процесса,
+// общие хранилища могут вместо него использовать собственные часы
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket состояние ведра; общая арифметика для всех хранилищ
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{tokens: float64(limit.Requests), updated: now}
+}
+
+// take пополняет ведро за прошедшее время и пытается забрать из него токен
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Requests), b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((float64(limit.Requests) - b.tokens) / rate)
+	return result
+}
+
+// idle сообщает, что ведро давно наполнилось и его можно забыть
+func (b *bucket) idle(limit Limit, now time.Time) bool {
+	return now.Sub(b.updated) >= limit.Period
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval как часто MemoryStore удаляет наполнившиеся вёдра
+const sweepInterval = time.Minute
+
+// MemoryStore хранит вёдра в памяти процесса; лимиты действуют на каждый экземпляр отдельно
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &memoryBucket{bucket: newBucket(limit, now), limit: limit}
+		s.buckets[key] = b
+	}
+	return b.take(limit, now), nil
+}
+
+// sweep удаляет полные вёдра: пересоздать их дешевле, чем хранить для каждого клиента
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.idle(b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-people-api/auth"
+	"go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limiter применяет лимиты к маршрутам; name отделяет вёдра разных групп маршрутов
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Middleware ограничивает частоту запросов клиента к маршруту.
+// Клиент определяется по ключу API или пользователю JWT, а без аутентификации — по IP,
+// поэтому middleware подключается после auth.Middleware. Отключённый лимит или nil Limiter ничего не делают.
+// При недоступном хранилище запросы пропускаются: лимитер не должен останавливать API.
+func (l *Limiter) Middleware(name string, limit Limit) gin.HandlerFunc {
+	return l.middleware(name, limit, clientKey)
+}
+
+// IPMiddleware ограничивает частоту запросов с одного IP независимо от учётных данных.
+// Подключается до auth.Middleware, чтобы перебор ключей и токенов тоже упирался в лимит.
+func (l *Limiter) IPMiddleware(name string, limit Limit) gin.HandlerFunc {
+	return l.middleware(name, limit, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+}
+
+func (l *Limiter) middleware(name string, limit Limit, key func(*gin.Context) string) gin.HandlerFunc {
+	if l == nil || !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		result, err := l.store.Take(ctx, name+":"+key(c), limit, l.now())
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Rate limiter unavailable, request allowed")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:     "rate_limited",
+				Message:   "Too many requests, retry later",
+				RequestID: requestid.FromContext(c.Request.Context()),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if principal, ok := auth.FromContext(c.Request.Context()); ok {
+		if principal.KeyID != 0 {
+			return "key:" + strconv.FormatInt(principal.KeyID, 10)
+		}
+		return "user:" + principal.UserID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"go-people-api/log"
+)
+
+// cleanupInterval как часто PostgresStore удаляет давно не используемые вёдра
+const cleanupInterval = 10 * time.Minute
+
+// PostgresStore хранит вёдра в таблице rate_limits, общей для всех экземпляров сервиса
+type PostgresStore struct {
+	db *sql.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+	// maxPeriod самый длинный период среди лимитов: раньше него ведро не наполнится
+	maxPeriod time.Duration
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take пополняет ведро и забирает токен в одной транзакции. Прошедшее время считается
+// по часам базы (NOW()), а не по now вызывающего: часы экземпляров могут расходиться.
+// UPSERT блокирует строку ведра, поэтому параллельные запросы к нему выполняются по очереди.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.cleanup(ctx, now, limit)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	var b bucket
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+		    tokens = LEAST($2, rate_limits.tokens + GREATEST(EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at), 0) * $3),
+		    updated_at = NOW()
+		RETURNING tokens, updated_at
+	`, key, float64(limit.Requests), limit.rate()).Scan(&b.tokens, &b.updated); err != nil {
+		return Result{}, err
+	}
+
+	// Ведро уже пополнено на момент b.updated, осталось забрать токен
+	result := b.take(limit, b.updated)
+	if _, err := tx.ExecContext(ctx, "UPDATE rate_limits SET tokens = $2 WHERE key = $1", key, b.tokens); err != nil {
+		return Result{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// cleanup не чаще раза в cleanupInterval удаляет вёдра, которые успели наполниться
+func (s *PostgresStore) cleanup(ctx context.Context, now time.Time, limit Limit) {
+	s.mu.Lock()
+	if limit.Period > s.maxPeriod {
+		s.maxPeriod = limit.Period
+	}
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	period := s.maxPeriod
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM rate_limits WHERE updated_at < NOW() - make_interval(secs => $1)", period.Seconds(),
+	); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("Failed to clean up rate limits")
+	}
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-people-api/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Limit
+		err   bool
+	}{
+		{"60/m", Limit{60, time.Minute}, false},
+		{"10/s", Limit{10, time.Second}, false},
+		{"1000/h", Limit{1000, time.Hour}, false},
+		{"30/10s", Limit{30, 10 * time.Second}, false},
+		{"", Limit{}, false},
+		{"0", Limit{}, false},
+		{"60", Limit{}, true},
+		{"x/m", Limit{}, true},
+		{"5/fortnight", Limit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.value)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	now := time.Now()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if res, _ := store.Take(ctx, "k", limit, now); !res.Allowed || res.Remaining != 1-i {
+			t.Fatalf("request %d: unexpected result %+v", i, res)
+		}
+	}
+
+	res, _ := store.Take(ctx, "k", limit, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Fatalf("expected denial with retry after 1s, got %+v", res)
+	}
+	if res, _ := store.Take(ctx, "other", limit, now); !res.Allowed {
+		t.Error("buckets must be independent per key")
+	}
+
+	// Через секунду появляется один токен
+	if res, _ := store.Take(ctx, "k", limit, now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected refilled token, got %+v", res)
+	}
+	// Простой дольше периода не накапливает больше Requests токенов
+	if res, _ := store.Take(ctx, "k", limit, now.Add(time.Hour)); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("expected full bucket, got %+v", res)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewLimiter(NewMemoryStore())
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if key := c.GetHeader("X-Test-Key"); key != "" {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), &auth.Principal{UserID: key}))
+		}
+	})
+	r.GET("/people", limiter.Middleware("create", Limit{Requests: 1, Period: time.Minute}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/people", nil)
+		req.Header.Set("X-Test-Key", key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("alice")
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" ||
+		w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Fatalf("unexpected first response %d %v", w.Code, w.Header())
+	}
+
+	w = do("alice")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+
+	// Другой клиент и клиент по IP получают свои вёдра
+	if w := do("bob"); w.Code != http.StatusOK {
+		t.Errorf("bob: expected 200, got %d", w.Code)
+	}
+	if w := do(""); w.Code != http.StatusOK {
+		t.Errorf("anonymous: expected 200, got %d", w.Code)
+	}
+}
+
+func TestIPMiddleware_BeforeAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewLimiter(NewMemoryStore())
+
+	r := gin.New()
+	r.Use(limiter.IPMiddleware("ip", Limit{Requests: 2, Period: time.Minute}))
+	// Неверные учётные данные: запрос отклоняется аутентификацией, но токен ведра уже потрачен
+	r.Use(func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+	r.GET("/people", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	codes := make([]int, 3)
+	for i := range codes {
+		req := httptest.NewRequest(http.MethodGet, "/people", nil)
+		req.Header.Set("X-API-Key", "guess-"+string(rune('a'+i)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes[i] = w.Code
+	}
+	if codes[0] != http.StatusUnauthorized || codes[1] != http.StatusUnauthorized || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("expected brute force to be limited by IP, got %v", codes)
+	}
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var limiter *Limiter
+
+	r := gin.New()
+	r.GET("/", limiter.Middleware("api", Limit{Requests: 1, Period: time.Minute}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("disabled limiter must not limit, got %d", w.Code)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-people-api/models"
+	"go-people-api/requestid"
+)
+
+// ErrHistoryNotFound запись истории не найдена или относится к другому человеку
+var ErrHistoryNotFound = errors.New("history entry not found")
+
+const defaultHistoryLimit = 50
+
+// historyFields поля, изменения которых попадают в changed_fields
+var historyFields = []string{
+	"name", "surname", "patronymic", "gender", "age", "nationality", "attributes", "enrichment_status", "deleted_at",
+}
+
+// changedFields сравнивает состояния человека; при before == nil изменёнными считаются все заполненные поля
+func changedFields(before, after *models.Person) []string {
+	if before == nil {
+		before = &models.Person{}
+	}
+	if after == nil {
+		after = &models.Person{}
+	}
+
+	changed := []string{}
+	for _, field := range historyFields {
+		if fieldValue(before, field) != fieldValue(after, field) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+func fieldValue(p *models.Person, field string) string {
+	switch field {
+	case "name":
+		return p.Name
+	case "surname":
+		return p.Surname
+	case "patronymic":
+		return p.Patronymic
+	case "gender":
+		return p.Gender
+	case "age":
+		return fmt.Sprint(p.Age)
+	case "nationality":
+		return p.Nationality
+	case "attributes":
+		if len(p.Attributes) == 0 {
+			return ""
+		}
+		// json.Marshal сортирует ключи, поэтому одинаковые карты дают одну строку
+		data, _ := json.Marshal(p.Attributes)
+		return string(data)
+	case "enrichment_status":
+		return p.EnrichmentStatus
+	case "deleted_at":
+		if p.DeletedAt == nil {
+			return ""
+		}
+		return p.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// newHistoryEntry собирает запись истории; идентификаторы запроса и пользователя
+// берутся из контекста так же, как в log.WithContext
+func newHistoryEntry(ctx context.Context, action string, before, after *models.Person) *models.PersonHistoryEntry {
+	entry := &models.PersonHistoryEntry{
+		Action:        action,
+		Before:        before,
+		After:         after,
+		ChangedFields: changedFields(before, after),
+		RequestID:     requestid.FromContext(ctx),
+		UserID:        requestid.UserIDFromContext(ctx),
+		CreatedAt:     time.Now(),
+	}
+	if after != nil {
+		entry.PersonID = after.ID
+	} else if before != nil {
+		entry.PersonID = before.ID
+	}
+	return entry
+}
+
+// revertTarget применяет к человеку данные из записи истории; служебные поля не меняются
+func revertTarget(current *models.Person, entry *models.PersonHistoryEntry) *models.Person {
+	source := entry.After
+	if source == nil {
+		source = entry.Before
+	}
+
+	target := *current
+	target.Name = source.Name
+	target.Surname = source.Surname
+	target.Patronymic = source.Patronymic
+	target.Gender = source.Gender
+	target.Age = source.Age
+	target.Nationality = source.Nationality
+	return &target
+}
+
+// testMatches проверяет условие JSON Patch "test" так же, как IS NOT DISTINCT FROM в Postgres
+func testMatches(p *models.Person, test models.FieldTest) bool {
+	expected := ""
+	switch v := testValue(test).(type) {
+	case nil:
+		if test.Field == "age" {
+			expected = "0"
+		}
+	default:
+		expected = fmt.Sprint(v)
+	}
+	return fieldValue(p, test.Field) == expected
+}
+
+// testValue приводит ожидаемое значение test к виду, общему для обоих хранилищ:
+// пустая строка и возраст 0 у очищаемых полей означают NULL
+func testValue(test models.FieldTest) interface{} {
+	if !nullableColumns[test.Field] {
+		return test.Value
+	}
+	switch v := test.Value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case int:
+		if v == 0 {
+			return nil
+		}
+	}
+	return test.Value
+}
+
+func setFieldNull(p *models.Person, field string) {
+	switch field {
+	case "patronymic":
+		p.Patronymic = ""
+	case "gender":
+		p.Gender = ""
+	case "age":
+		p.Age = 0
+	case "nationality":
+		p.Nationality = ""
+	}
+}
+
+func historyLimit(filter models.HistoryFilter) int {
+	if filter.Limit == 0 {
+		return defaultHistoryLimit
+	}
+	return filter.Limit
+}
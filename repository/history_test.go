@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-people-api/models"
+)
+
+// Одно и то же условие test проверяется памятью и попадает в UPDATE для Postgres
+// с одинаковым смыслом: пустое значение очищаемого поля равно null
+func TestPatchTests_MemoryAndPostgresAgree(t *testing.T) {
+	name := "Petr"
+	cases := []struct {
+		test      models.FieldTest
+		match     bool
+		column    string
+		wantValue interface{}
+	}{
+		{models.FieldTest{Field: "patronymic", Value: nil}, true, "NULLIF(patronymic, '')", nil},
+		{models.FieldTest{Field: "patronymic", Value: ""}, true, "NULLIF(patronymic, '')", nil},
+		{models.FieldTest{Field: "age", Value: 0}, true, "NULLIF(age, 0)", nil},
+		{models.FieldTest{Field: "gender", Value: nil}, false, "gender", nil},
+		{models.FieldTest{Field: "gender", Value: ""}, false, "gender", nil},
+		{models.FieldTest{Field: "gender", Value: "male"}, true, "gender", "male"},
+		{models.FieldTest{Field: "nationality", Value: ""}, true, "NULLIF(nationality, '')", nil},
+		{models.FieldTest{Field: "name", Value: ""}, false, "name", ""},
+	}
+
+	for _, tc := range cases {
+		repo := NewMemoryPersonRepository()
+		_ = repo.Create(context.Background(), &models.Person{Name: "Ivan", Surname: "Petrov", Gender: "male"})
+
+		input := models.UpdatePersonRequest{Name: &name, Tests: []models.FieldTest{tc.test}}
+		_, err := repo.Patch(context.Background(), 1, input, 0)
+		if matched := !errors.Is(err, ErrTestFailed); matched != tc.match || (matched && err != nil) {
+			t.Errorf("%+v: memory match = %v (%v), want %v", tc.test, matched, err, tc.match)
+		}
+
+		query, args := buildPartialUpdateQuery(1, input)
+		if !strings.Contains(query, " AND "+tc.column+" IS NOT DISTINCT FROM $3") {
+			t.Errorf("%+v: unexpected query %s", tc.test, query)
+		}
+		// gender — enum gender_type: сравнение с '' Postgres отвергает ошибкой 22P02
+		if strings.Contains(query, "gender, ''") {
+			t.Errorf("%+v: enum column compared with empty string: %s", tc.test, query)
+		}
+		if got := args[len(args)-1]; got != tc.wantValue {
+			t.Errorf("%+v: postgres value = %#v, want %#v", tc.test, got, tc.wantValue)
+		}
+	}
+}
+
+func TestApplyEnrichment_MergesAttributes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryPersonRepository()
+	_ = repo.Create(ctx, &models.Person{Name: "Ivan", Surname: "Petrov", Attributes: map[string]string{"locale": "ru_RU"}})
+
+	enriched := &models.Person{Attributes: map[string]string{"locale": "en_US", "ethnicity": "slavic"}}
+	if err := repo.ApplyEnrichment(ctx, 1, enriched, models.EnrichmentCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	person, _ := repo.Get(ctx, 1, false)
+	// Уже известные атрибуты обогащение не перезаписывает
+	if person.Attributes["locale"] != "ru_RU" || person.Attributes["ethnicity"] != "slavic" {
+		t.Errorf("unexpected attributes %v", person.Attributes)
+	}
+
+	page, _ := repo.History(ctx, 1, models.HistoryFilter{Limit: 1})
+	entry := page.Data[0]
+	if !slices.Contains(entry.ChangedFields, "attributes") || len(entry.Before.Attributes) != 1 {
+		t.Errorf("unexpected history entry %+v", entry)
+	}
+}
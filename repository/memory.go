@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-people-api/models"
+)
+
+// MemoryPersonRepository хранит людей в памяти процесса (для тестов и локального запуска)
+type MemoryPersonRepository struct {
+	mu            sync.RWMutex
+	people        map[int]models.Person
+	nextID        int
+	history       []models.PersonHistoryEntry
+	nextHistoryID int64
+}
+
+func NewMemoryPersonRepository() *MemoryPersonRepository {
+	return &MemoryPersonRepository{
+		people: make(map[int]models.Person),
+		nextID: 1,
+	}
+}
+
+func (r *MemoryPersonRepository) Create(ctx context.Context, person *models.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if person.EnrichmentStatus == "" {
+		person.EnrichmentStatus = models.EnrichmentCompleted
+	}
+	person.ID = r.nextID
+	person.Version = 1
+	person.DeletedAt = nil
+	person.CreatedAt = now
+	person.UpdatedAt = now
+	r.nextID++
+
+	stored := *person
+	stored.Attributes = maps.Clone(person.Attributes)
+	r.people[person.ID] = stored
+	after := *person
+	r.record(ctx, models.HistoryCreate, nil, &after)
+	return nil
+}
+
+func (r *MemoryPersonRepository) CreateBatch(ctx context.Context, people []*models.Person) ([]error, error) {
+	errs := make([]error, len(people))
+	for i, person := range people {
+		errs[i] = r.Create(ctx, person)
+	}
+	return errs, nil
+}
+
+func (r *MemoryPersonRepository) Get(_ context.Context, id int, includeDeleted bool) (*models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	person, ok := r.people[id]
+	if !ok || (person.DeletedAt != nil && !includeDeleted) {
+		return nil, ErrNotFound
+	}
+	return &person, nil
+}
+
+func (r *MemoryPersonRepository) List(_ context.Context, filter models.PersonFilter) (*models.PeoplePage, error) {
+	page, err := newPageRequest(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var anchor models.Person
+	if page.cursor != nil {
+		anchor.ID = page.cursor.ID
+		if err := setSortValue(&anchor, page.sort.field, page.cursor.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	matched := make([]models.Person, 0, len(r.people))
+	for _, p := range r.people {
+		if matchesFilter(p, filter) {
+			matched = append(matched, p)
+		}
+	}
+	r.mu.RUnlock()
+
+	desc := page.sort.desc != page.backward()
+	sort.Slice(matched, func(i, j int) bool {
+		c := comparePeople(matched[i], matched[j], page.sort.field)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	total := len(matched)
+	start := 0
+	switch {
+	case page.cursor != nil:
+		start = sort.Search(len(matched), func(i int) bool {
+			c := comparePeople(matched[i], anchor, page.sort.field)
+			if desc {
+				return c < 0
+			}
+			return c > 0
+		})
+	case page.offset < len(matched):
+		start = page.offset
+	default:
+		start = len(matched)
+	}
+
+	end := start + page.limit + 1
+	if end > len(matched) {
+		end = len(matched)
+	}
+	people := append([]models.Person(nil), matched[start:end]...)
+
+	result := buildPage(page, people, total)
+	return &result, nil
+}
+
+func (r *MemoryPersonRepository) Stream(ctx context.Context, filter models.PersonFilter, fn func(*models.Person) error) error {
+	filter.Limit, filter.Offset, filter.Cursor = streamBatchSize, 0, ""
+	for {
+		page, err := r.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for i := range page.Data {
+			if err := fn(&page.Data[i]); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func (r *MemoryPersonRepository) Update(ctx context.Context, id int, person *models.Person, ifVersion int) (*models.Person, error) {
+	return r.mutate(ctx, id, models.HistoryUpdate, ifVersion, func(existing models.Person) (models.Person, error) {
+		if existing.DeletedAt != nil {
+			return existing, ErrNotFound
+		}
+		updated := *person
+		updated.ID = id
+		updated.CreatedAt = existing.CreatedAt
+		updated.EnrichmentStatus = existing.EnrichmentStatus
+		updated.Attributes = existing.Attributes
+		updated.Version = existing.Version
+		updated.DeletedAt = nil
+		return updated, nil
+	})
+}
+
+func (r *MemoryPersonRepository) Patch(ctx context.Context, id int, input models.UpdatePersonRequest, ifVersion int) (*models.Person, error) {
+	if input.IsEmpty() {
+		return nil, ErrNoFields
+	}
+
+	return r.mutate(ctx, id, models.HistoryPatch, ifVersion, func(person models.Person) (models.Person, error) {
+		if person.DeletedAt != nil {
+			return person, ErrNotFound
+		}
+		for _, test := range input.Tests {
+			if !testMatches(&person, test) {
+				return person, ErrTestFailed
+			}
+		}
+		if input.Name != nil {
+			person.Name = *input.Name
+		}
+		if input.Surname != nil {
+			person.Surname = *input.Surname
+		}
+		if input.Patronymic != nil {
+			person.Patronymic = *input.Patronymic
+		}
+		if input.Age != nil {
+			person.Age = *input.Age
+		}
+		if input.Gender != nil {
+			person.Gender = *input.Gender
+		}
+		if input.Nationality != nil {
+			person.Nationality = *input.Nationality
+		}
+		for _, field := range input.Null {
+			setFieldNull(&person, field)
+		}
+		return person, nil
+	})
+}
+
+func (r *MemoryPersonRepository) Delete(ctx context.Context, id int, ifVersion int) error {
+	_, err := r.mutate(ctx, id, models.HistoryDelete, ifVersion, func(person models.Person) (models.Person, error) {
+		if person.DeletedAt != nil {
+			return person, ErrNotFound
+		}
+		now := time.Now()
+		person.DeletedAt = &now
+		return person, nil
+	})
+	return err
+}
+
+func (r *MemoryPersonRepository) Restore(ctx context.Context, id int) (*models.Person, error) {
+	return r.mutate(ctx, id, models.HistoryRestore, 0, func(person models.Person) (models.Person, error) {
+		if person.DeletedAt == nil {
+			return person, ErrNotDeleted
+		}
+		person.DeletedAt = nil
+		return person, nil
+	})
+}
+
+func (r *MemoryPersonRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []int
+	for id, person := range r.people {
+		if person.DeletedAt != nil && person.DeletedAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	// История остаётся, окончательное удаление само попадает в неё
+	for _, id := range ids {
+		person := r.people[id]
+		delete(r.people, id)
+		r.record(ctx, models.HistoryPurge, &person, nil)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *MemoryPersonRepository) ApplyEnrichment(ctx context.Context, id int, enriched *models.Person, status string) error {
+	_, err := r.mutate(ctx, id, models.HistoryEnrich, 0, func(person models.Person) (models.Person, error) {
+		if person.DeletedAt != nil {
+			return person, ErrNotFound
+		}
+		if enriched != nil {
+			if person.Age == 0 {
+				person.Age = enriched.Age
+			}
+			if person.Gender == "" {
+				person.Gender = enriched.Gender
+			}
+			if person.Nationality == "" {
+				person.Nationality = enriched.Nationality
+			}
+			// MergeAttributes создаёт новую карту: старая остаётся в записи истории
+			person.Attributes = models.MergeAttributes(person.Attributes, enriched.Attributes)
+		}
+		person.EnrichmentStatus = status
+		return person, nil
+	})
+	return err
+}
+
+func (r *MemoryPersonRepository) History(_ context.Context, personID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := &models.HistoryPage{
+		Data:   []models.PersonHistoryEntry{},
+		Limit:  historyLimit(filter),
+		Offset: filter.Offset,
+	}
+	skipped := 0
+	for i := len(r.history) - 1; i >= 0; i-- {
+		entry := r.history[i]
+		if entry.PersonID != personID {
+			continue
+		}
+		page.Total++
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if len(page.Data) < page.Limit {
+			page.Data = append(page.Data, entry)
+		}
+	}
+	return page, nil
+}
+
+func (r *MemoryPersonRepository) Revert(ctx context.Context, id int, historyID int64) (*models.Person, error) {
+	return r.mutate(ctx, id, models.HistoryRevert, 0, func(person models.Person) (models.Person, error) {
+		if person.DeletedAt != nil {
+			return person, ErrNotFound
+		}
+		for _, entry := range r.history {
+			if entry.ID == historyID && entry.PersonID == id {
+				return *revertTarget(&person, &entry), nil
+			}
+		}
+		return person, ErrHistoryNotFound
+	})
+}
+
+// mutate повторяет PostgresPersonRepository.mutate: change получает копию человека
+// и возвращает новое состояние, которое сохраняется вместе с записью истории
+func (r *MemoryPersonRepository) mutate(ctx context.Context, id int, action string, ifVersion int, change func(models.Person) (models.Person, error)) (*models.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, ok := r.people[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if ifVersion != 0 && before.Version != ifVersion {
+		return nil, ErrVersionMismatch
+	}
+
+	after, err := change(before)
+	if err != nil {
+		return nil, err
+	}
+	after.Version = before.Version + 1
+	after.UpdatedAt = time.Now()
+	r.people[id] = after
+
+	r.record(ctx, action, &before, &after)
+	return &after, nil
+}
+
+// record добавляет запись истории; вызывается под блокировкой
+func (r *MemoryPersonRepository) record(ctx context.Context, action string, before, after *models.Person) {
+	entry := newHistoryEntry(ctx, action, before, after)
+	if len(entry.ChangedFields) == 0 {
+		return
+	}
+	r.nextHistoryID++
+	entry.ID = r.nextHistoryID
+	r.history = append(r.history, *entry)
+}
+
+// matchesFilter повторяет условия buildWhereClause
+func matchesFilter(p models.Person, filter models.PersonFilter) bool {
+	if p.DeletedAt != nil && !filter.IncludeDeleted {
+		return false
+	}
+	if filter.Name != "" && !containsFold(p.Name, filter.Name) {
+		return false
+	}
+	if filter.Surname != "" && !containsFold(p.Surname, filter.Surname) {
+		return false
+	}
+	if filter.AgeFrom != nil && (p.Age == 0 || p.Age < *filter.AgeFrom) {
+		return false
+	}
+	if filter.AgeTo != nil && (p.Age == 0 || p.Age > *filter.AgeTo) {
+		return false
+	}
+	if filter.Gender != "" && p.Gender != filter.Gender {
+		return false
+	}
+	if filter.Nationality != "" && p.Nationality != filter.Nationality {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-people-api/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	streamBatchSize  = 500
+	defaultSort      = "-created_at"
+)
+
+// sortColumn описывает колонку, по которой разрешена сортировка
+type sortColumn struct {
+	expr string // SQL-выражение для ORDER BY и сравнения с курсором
+	cast string // тип, к которому приводится значение курсора
+}
+
+var sortColumns = map[string]sortColumn{
+	"id":         {expr: "id", cast: "int"},
+	"name":       {expr: "name", cast: "text"},
+	"surname":    {expr: "surname", cast: "text"},
+	"age":        {expr: "COALESCE(age, 0)", cast: "int"},
+	"created_at": {expr: "created_at", cast: "timestamptz"},
+	"updated_at": {expr: "updated_at", cast: "timestamptz"},
+}
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort parameter")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+type sortSpec struct {
+	field  string
+	column sortColumn
+	desc   bool
+}
+
+// parseSort разбирает параметр sort вида "age" или "-created_at"
+func parseSort(raw string) (sortSpec, error) {
+	if raw == "" {
+		raw = defaultSort
+	}
+
+	spec := sortSpec{field: raw}
+	if strings.HasPrefix(raw, "-") {
+		spec.desc = true
+		spec.field = raw[1:]
+	}
+
+	column, ok := sortColumns[spec.field]
+	if !ok {
+		return sortSpec{}, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, spec.field)
+	}
+	spec.column = column
+	return spec, nil
+}
+
+func (s sortSpec) String() string {
+	if s.desc {
+		return "-" + s.field
+	}
+	return s.field
+}
+
+// pageCursor указывает на строку, от которой продолжается выборка
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+	Prev  bool   `json:"p,omitempty"`
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// setSortValue восстанавливает значение поля сортировки из курсора
+func setSortValue(p *models.Person, field, value string) error {
+	var err error
+	switch field {
+	case "id":
+		p.ID, err = strconv.Atoi(value)
+	case "name":
+		p.Name = value
+	case "surname":
+		p.Surname = value
+	case "age":
+		p.Age, err = strconv.Atoi(value)
+	case "created_at":
+		p.CreatedAt, err = time.Parse(time.RFC3339Nano, value)
+	case "updated_at":
+		p.UpdatedAt, err = time.Parse(time.RFC3339Nano, value)
+	}
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// comparePeople сравнивает двух людей по полю сортировки, при равенстве — по id
+func comparePeople(a, b models.Person, field string) int {
+	var c int
+	switch field {
+	case "name":
+		c = strings.Compare(a.Name, b.Name)
+	case "surname":
+		c = strings.Compare(a.Surname, b.Surname)
+	case "age":
+		c = a.Age - b.Age
+	case "created_at":
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return a.ID - b.ID
+}
+
+// pageRequest содержит разобранные параметры пагинации
+type pageRequest struct {
+	sort   sortSpec
+	cursor *pageCursor
+	limit  int
+	offset int
+}
+
+func newPageRequest(filter models.PersonFilter) (pageRequest, error) {
+	sort, err := parseSort(filter.Sort)
+	if err != nil {
+		return pageRequest{}, err
+	}
+
+	page := pageRequest{
+		sort:   sort,
+		limit:  filter.Limit,
+		offset: filter.Offset,
+	}
+	if page.limit == 0 {
+		page.limit = defaultPageLimit
+	}
+
+	if filter.Cursor != "" {
+		if filter.Offset != 0 {
+			return pageRequest{}, fmt.Errorf("%w: cursor and offset are mutually exclusive", ErrInvalidCursor)
+		}
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return pageRequest{}, err
+		}
+		if cursor.Sort != sort.String() {
+			return pageRequest{}, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, cursor.Sort)
+		}
+		page.cursor = cursor
+	}
+
+	return page, nil
+}
+
+// newStreamRequest параметры обхода всей выборки порциями; пагинация из фильтра игнорируется
+func newStreamRequest(filter models.PersonFilter) (pageRequest, error) {
+	sort, err := parseSort(filter.Sort)
+	if err != nil {
+		return pageRequest{}, err
+	}
+	return pageRequest{sort: sort, limit: streamBatchSize}, nil
+}
+
+// advance переводит курсор за последнюю выбранную строку
+func (p *pageRequest) advance(last models.Person) {
+	p.cursor = &pageCursor{
+		Sort:  p.sort.String(),
+		Value: sortValue(last, p.sort.field),
+		ID:    last.ID,
+	}
+}
+
+// backward сообщает, что выборка идёт к предыдущей странице
+func (p pageRequest) backward() bool {
+	return p.cursor != nil && p.cursor.Prev
+}
+
+func (p pageRequest) cursorFor(person models.Person, prev bool) string {
+	return encodeCursor(pageCursor{
+		Sort:  p.sort.String(),
+		Value: sortValue(person, p.sort.field),
+		ID:    person.ID,
+		Prev:  prev,
+	})
+}
+
+func sortValue(p models.Person, field string) string {
+	switch field {
+	case "id":
+		return strconv.Itoa(p.ID)
+	case "name":
+		return p.Name
+	case "surname":
+		return p.Surname
+	case "age":
+		return strconv.Itoa(p.Age)
+	case "created_at":
+		return p.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return p.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// buildPage собирает ответ из выбранных строк (выбирается limit+1 строка,
+// чтобы понять, есть ли следующая страница)
+func buildPage(page pageRequest, people []models.Person, total int) models.PeoplePage {
+	hasMore := len(people) > page.limit
+	if hasMore {
+		people = people[:page.limit]
+	}
+
+	if page.backward() {
+		for i, j := 0, len(people)-1; i < j; i, j = i+1, j-1 {
+			people[i], people[j] = people[j], people[i]
+		}
+	}
+
+	result := models.PeoplePage{
+		Data:   people,
+		Total:  total,
+		Limit:  page.limit,
+		Offset: page.offset,
+		Sort:   page.sort.String(),
+	}
+	if len(people) == 0 {
+		return result
+	}
+
+	first, last := people[0], people[len(people)-1]
+	switch {
+	case page.backward():
+		result.NextCursor = page.cursorFor(last, false)
+		if hasMore {
+			result.PrevCursor = page.cursorFor(first, true)
+		}
+	default:
+		if hasMore {
+			result.NextCursor = page.cursorFor(last, false)
+		}
+		if page.cursor != nil || page.offset > 0 {
+			result.PrevCursor = page.cursorFor(first, true)
+		}
+	}
+
+	return result
+}
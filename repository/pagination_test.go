@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := pageCursor{Sort: "-created_at", Value: "2025-06-08T17:19:09.5Z", ID: 42, Prev: true}
+
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, raw := range []string{"not base64!", base64.RawURLEncoding.EncodeToString([]byte("not json"))} {
+		if _, err := decodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: expected ErrInvalidCursor, got %v", raw, err)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	spec, err := parseSort("")
+	if err != nil || spec.String() != defaultSort || !spec.desc {
+		t.Errorf("expected default sort %s, got %+v (%v)", defaultSort, spec, err)
+	}
+
+	spec, err = parseSort("-age")
+	if err != nil || spec.field != "age" || !spec.desc || spec.column.expr != "COALESCE(age, 0)" {
+		t.Errorf("unexpected spec for -age: %+v (%v)", spec, err)
+	}
+
+	// Сортировать можно только по колонкам из белого списка
+	for _, raw := range []string{"password", "-", "name; DROP TABLE people", "gender"} {
+		if _, err := parseSort(raw); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("%q: expected ErrInvalidSort, got %v", raw, err)
+		}
+	}
+}
+
+func TestNewPageRequest(t *testing.T) {
+	page, err := newPageRequest(models.PersonFilter{})
+	if err != nil || page.limit != defaultPageLimit || page.cursor != nil {
+		t.Errorf("unexpected defaults: %+v (%v)", page, err)
+	}
+
+	cursor := encodeCursor(pageCursor{Sort: "age", Value: "30", ID: 3})
+	page, err = newPageRequest(models.PersonFilter{Sort: "age", Cursor: cursor, Limit: 5})
+	if err != nil || page.limit != 5 || page.cursor == nil || page.cursor.ID != 3 {
+		t.Errorf("unexpected cursor page: %+v (%v)", page, err)
+	}
+
+	cases := map[string]models.PersonFilter{
+		"cursor with offset":     {Sort: "age", Cursor: cursor, Offset: 10},
+		"cursor for other sort":  {Sort: "-age", Cursor: cursor},
+		"cursor is not a cursor": {Cursor: "garbage"},
+	}
+	for name, filter := range cases {
+		if _, err := newPageRequest(filter); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: expected ErrInvalidCursor, got %v", name, err)
+		}
+	}
+}
+
+func TestBuildPage(t *testing.T) {
+	people := func(ages ...int) []models.Person {
+		result := make([]models.Person, len(ages))
+		for i, age := range ages {
+			result[i] = models.Person{ID: age, Age: age, CreatedAt: time.Unix(int64(age), 0)}
+		}
+		return result
+	}
+
+	first, _ := newPageRequest(models.PersonFilter{Sort: "age", Limit: 2})
+	page := buildPage(first, people(10, 20, 30), 5)
+	if len(page.Data) != 2 || page.Total != 5 || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	next, err := decodeCursor(page.NextCursor)
+	if err != nil || next.Value != "20" || next.ID != 20 || next.Prev {
+		t.Errorf("next cursor must point after the last row: %+v (%v)", next, err)
+	}
+
+	last, _ := newPageRequest(models.PersonFilter{Sort: "age", Limit: 2, Cursor: page.NextCursor})
+	page = buildPage(last, people(30), 5)
+	if len(page.Data) != 1 || page.NextCursor != "" || page.PrevCursor == "" {
+		t.Errorf("unexpected last page: %+v", page)
+	}
+
+	// Назад строки выбираются в обратном порядке и разворачиваются
+	back, _ := newPageRequest(models.PersonFilter{Sort: "age", Limit: 2,
+		Cursor: encodeCursor(pageCursor{Sort: "age", Value: "30", ID: 30, Prev: true})})
+	page = buildPage(back, people(20, 10), 5)
+	if page.Data[0].Age != 10 || page.Data[1].Age != 20 || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Errorf("unexpected previous page: %+v", page)
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-people-api/models"
+)
+
+var (
+	ErrNotFound = errors.New("person not found")
+	ErrNoFields = errors.New("no fields to update")
+	// ErrVersionMismatch версия человека отличается от ожидаемой (If-Match)
+	ErrVersionMismatch = errors.New("person version mismatch")
+	// ErrTestFailed не выполнено условие операции JSON Patch "test"
+	ErrTestFailed = errors.New("patch test failed")
+	// ErrNotDeleted восстановление человека, который не был удалён
+	ErrNotDeleted = errors.New("person is not deleted")
+)
+
+// PersonRepository описывает хранилище людей.
+// Каждое изменение записывается в историю вместе с состоянием до и после.
+type PersonRepository interface {
+	// Create сохраняет человека и заполняет ID, CreatedAt и UpdatedAt
+	Create(ctx context.Context, person *models.Person) error
+	// CreateBatch сохраняет людей одной транзакцией; ошибки отдельных записей
+	// возвращаются по индексам, общая ошибка означает, что не сохранено ничего
+	CreateBatch(ctx context.Context, people []*models.Person) ([]error, error)
+	// Get возвращает человека; удалённые находятся только при includeDeleted
+	Get(ctx context.Context, id int, includeDeleted bool) (*models.Person, error)
+	List(ctx context.Context, filter models.PersonFilter) (*models.PeoplePage, error)
+	// Stream передаёт в fn всех людей, подходящих под фильтр, в порядке filter.Sort.
+	// Limit, Offset и Cursor фильтра не учитываются.
+	Stream(ctx context.Context, filter models.PersonFilter, fn func(*models.Person) error) error
+	// Update, Patch и Delete при ifVersion != 0 изменяют человека, только если его версия
+	// равна ifVersion, иначе возвращают ErrVersionMismatch. Каждое изменение увеличивает версию.
+	Update(ctx context.Context, id int, person *models.Person, ifVersion int) (*models.Person, error)
+	Patch(ctx context.Context, id int, input models.UpdatePersonRequest, ifVersion int) (*models.Person, error)
+	// Delete помечает человека удалённым (deleted_at); запись остаётся до Purge
+	Delete(ctx context.Context, id int, ifVersion int) error
+	// Restore снимает пометку удаления
+	Restore(ctx context.Context, id int) (*models.Person, error)
+	// Purge окончательно удаляет людей, помеченных удалёнными раньше before
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// ApplyEnrichment заполняет незаданные поля данными обогащения и выставляет его статус
+	ApplyEnrichment(ctx context.Context, id int, enriched *models.Person, status string) error
+
+	// History возвращает историю изменений человека, от новых записей к старым
+	History(ctx context.Context, personID int, filter models.HistoryFilter) (*models.HistoryPage, error)
+	// Revert возвращает данные человека к состоянию после записи истории historyID
+	Revert(ctx context.Context, id int, historyID int64) (*models.Person, error)
+}
@@ -0,0 +1,752 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-people-api/models"
+
+	"github.com/lib/pq"
+)
+
+const personColumns = "id, name, surname, patronymic, age, gender, nationality, created_at, updated_at, enrichment_status, deleted_at, version, attributes"
+
+// PostgresPersonRepository хранит людей в таблице people
+type PostgresPersonRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPersonRepository(db *sql.DB) *PostgresPersonRepository {
+	return &PostgresPersonRepository{db: db}
+}
+
+func (r *PostgresPersonRepository) Create(ctx context.Context, person *models.Person) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertPerson(ctx, tx, person); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateBatch вставляет людей в одной транзакции. Каждая строка обёрнута в SAVEPOINT,
+// чтобы ошибка одной записи не откатывала остальные.
+func (r *PostgresPersonRepository) CreateBatch(ctx context.Context, people []*models.Person) ([]error, error) {
+	return r.CreateBatchWith(ctx, people, nil)
+}
+
+// CreateBatchWith как CreateBatch, но после вставки каждого человека вызывает then в той же
+// транзакции; ошибка then откатывает эту запись так же, как ошибка вставки
+func (r *PostgresPersonRepository) CreateBatchWith(ctx context.Context, people []*models.Person, then func(ctx context.Context, tx *sql.Tx, person *models.Person) error) ([]error, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	errs := make([]error, len(people))
+	for i, person := range people {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+		errs[i] = insertPerson(ctx, tx, person)
+		if errs[i] == nil && then != nil {
+			errs[i] = then(ctx, tx, person)
+		}
+		if errs[i] != nil {
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// insertPerson вставляет человека и записывает создание в историю
+func insertPerson(ctx context.Context, tx *sql.Tx, person *models.Person) error {
+	if person.EnrichmentStatus == "" {
+		person.EnrichmentStatus = models.EnrichmentCompleted
+	}
+
+	query := `
+		INSERT INTO people
+		(name, surname, patronymic, gender, age, nationality, enrichment_status, attributes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at, version
+	`
+
+	err := tx.QueryRowContext(ctx, query,
+		person.Name,
+		person.Surname,
+		nullString(person.Patronymic),
+		nullString(person.Gender),
+		nullInt(person.Age),
+		nullString(person.Nationality),
+		person.EnrichmentStatus,
+		attributesJSON(person.Attributes),
+	).Scan(&person.ID, &person.CreatedAt, &person.UpdatedAt, &person.Version)
+	if err != nil {
+		return err
+	}
+
+	after := *person
+	after.DeletedAt = nil
+	return insertHistory(ctx, tx, newHistoryEntry(ctx, models.HistoryCreate, nil, &after))
+}
+
+func (r *PostgresPersonRepository) Get(ctx context.Context, id int, includeDeleted bool) (*models.Person, error) {
+	query := "SELECT " + personColumns + " FROM people WHERE id = $1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	person, err := scanPerson(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+func (r *PostgresPersonRepository) List(ctx context.Context, filter models.PersonFilter) (*models.PeoplePage, error) {
+	page, err := newPageRequest(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := buildFilterQuery(filter, page)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	people := make([]models.Person, 0, page.limit+1)
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		people = append(people, *person)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var total int
+	countQuery, countArgs := buildCountQuery(filter)
+	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	result := buildPage(page, people, total)
+	return &result, nil
+}
+
+// Stream выбирает строки порциями по keyset-курсору, поэтому выгрузка
+// большой таблицы не держит в памяти больше одной порции
+func (r *PostgresPersonRepository) Stream(ctx context.Context, filter models.PersonFilter, fn func(*models.Person) error) error {
+	page, err := newStreamRequest(filter)
+	if err != nil {
+		return err
+	}
+
+	for {
+		query, args := buildFilterQuery(filter, page)
+		people, err := r.query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		hasMore := len(people) > page.limit
+		if hasMore {
+			people = people[:page.limit]
+		}
+		for i := range people {
+			if err := fn(&people[i]); err != nil {
+				return err
+			}
+		}
+		if !hasMore {
+			return nil
+		}
+		page.advance(people[len(people)-1])
+	}
+}
+
+func (r *PostgresPersonRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Person, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var people []models.Person
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		people = append(people, *person)
+	}
+	return people, rows.Err()
+}
+
+func (r *PostgresPersonRepository) Update(ctx context.Context, id int, person *models.Person, ifVersion int) (*models.Person, error) {
+	query := `
+		UPDATE people
+		SET name = $1, surname = $2, patronymic = $3, age = $4,
+		    gender = $5, nationality = $6
+		WHERE id = $7
+		RETURNING ` + personColumns
+
+	return r.mutate(ctx, id, models.HistoryUpdate, ifVersion, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt != nil {
+			return nil, ErrNotFound
+		}
+		return scanPerson(tx.QueryRowContext(ctx, query,
+			person.Name, person.Surname, nullString(person.Patronymic), nullInt(person.Age),
+			nullString(person.Gender), nullString(person.Nationality), id,
+		))
+	})
+}
+
+func (r *PostgresPersonRepository) Patch(ctx context.Context, id int, input models.UpdatePersonRequest, ifVersion int) (*models.Person, error) {
+	query, args := buildPartialUpdateQuery(id, input)
+	if query == "" {
+		return nil, ErrNoFields
+	}
+
+	return r.mutate(ctx, id, models.HistoryPatch, ifVersion, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt != nil {
+			return nil, ErrNotFound
+		}
+		after, err := scanPerson(tx.QueryRowContext(ctx, query, args...))
+		if errors.Is(err, sql.ErrNoRows) {
+			// Строка заблокирована в mutate, значит не выполнено условие test
+			return nil, ErrTestFailed
+		}
+		return after, err
+	})
+}
+
+func (r *PostgresPersonRepository) Delete(ctx context.Context, id int, ifVersion int) error {
+	query := "UPDATE people SET deleted_at = NOW() WHERE id = $1 RETURNING " + personColumns
+
+	_, err := r.mutate(ctx, id, models.HistoryDelete, ifVersion, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt != nil {
+			return nil, ErrNotFound
+		}
+		return scanPerson(tx.QueryRowContext(ctx, query, id))
+	})
+	return err
+}
+
+func (r *PostgresPersonRepository) Restore(ctx context.Context, id int) (*models.Person, error) {
+	query := "UPDATE people SET deleted_at = NULL WHERE id = $1 RETURNING " + personColumns
+
+	return r.mutate(ctx, id, models.HistoryRestore, 0, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt == nil {
+			return nil, ErrNotDeleted
+		}
+		return scanPerson(tx.QueryRowContext(ctx, query, id))
+	})
+}
+
+// Purge удаляет людей одной транзакцией и записывает каждое удаление в историю;
+// у people_history нет внешнего ключа на people, поэтому история остаётся
+func (r *PostgresPersonRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "DELETE FROM people WHERE deleted_at < $1 RETURNING "+personColumns, before)
+	if err != nil {
+		return 0, err
+	}
+	var purged []*models.Person
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		purged = append(purged, person)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, person := range purged {
+		if err := insertHistory(ctx, tx, newHistoryEntry(ctx, models.HistoryPurge, person, nil)); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+func (r *PostgresPersonRepository) ApplyEnrichment(ctx context.Context, id int, enriched *models.Person, status string) error {
+	if enriched == nil {
+		enriched = &models.Person{}
+	}
+
+	query := `
+		UPDATE people
+		SET age = COALESCE(age, $1),
+		    gender = COALESCE(gender, $2),
+		    nationality = COALESCE(nationality, $3),
+		    attributes = $6::jsonb || attributes,
+		    enrichment_status = $4
+		WHERE id = $5
+		RETURNING ` + personColumns
+
+	_, err := r.mutate(ctx, id, models.HistoryEnrich, 0, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt != nil {
+			return nil, ErrNotFound
+		}
+		return scanPerson(tx.QueryRowContext(ctx, query,
+			nullInt(enriched.Age), nullString(enriched.Gender), nullString(enriched.Nationality), status, id,
+			attributesJSON(enriched.Attributes),
+		))
+	})
+	return err
+}
+
+func (r *PostgresPersonRepository) History(ctx context.Context, personID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	page := &models.HistoryPage{
+		Data:   []models.PersonHistoryEntry{},
+		Limit:  historyLimit(filter),
+		Offset: filter.Offset,
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+historyColumns+" FROM people_history WHERE person_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3",
+		personID, page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Data = append(page.Data, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM people_history WHERE person_id = $1", personID).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (r *PostgresPersonRepository) Revert(ctx context.Context, id int, historyID int64) (*models.Person, error) {
+	query := `
+		UPDATE people
+		SET name = $1, surname = $2, patronymic = $3, age = $4,
+		    gender = $5, nationality = $6
+		WHERE id = $7
+		RETURNING ` + personColumns
+
+	return r.mutate(ctx, id, models.HistoryRevert, 0, func(tx *sql.Tx, before *models.Person) (*models.Person, error) {
+		if before.DeletedAt != nil {
+			return nil, ErrNotFound
+		}
+
+		entry, err := scanHistoryEntry(tx.QueryRowContext(ctx,
+			"SELECT "+historyColumns+" FROM people_history WHERE id = $1 AND person_id = $2", historyID, id,
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrHistoryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		target := revertTarget(before, entry)
+		return scanPerson(tx.QueryRowContext(ctx, query,
+			target.Name, target.Surname, nullString(target.Patronymic), nullInt(target.Age),
+			nullString(target.Gender), nullString(target.Nationality), id,
+		))
+	})
+}
+
+// mutate изменяет человека в транзакции: блокирует строку, сверяет версию (если ifVersion != 0),
+// передаёт change состояние до изменения и записывает в people_history оба состояния.
+// Изменения без отличий в историю не попадают.
+func (r *PostgresPersonRepository) mutate(ctx context.Context, id int, action string, ifVersion int, change func(tx *sql.Tx, before *models.Person) (*models.Person, error)) (*models.Person, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := scanPerson(tx.QueryRowContext(ctx, "SELECT "+personColumns+" FROM people WHERE id = $1 FOR UPDATE", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ifVersion != 0 && before.Version != ifVersion {
+		return nil, ErrVersionMismatch
+	}
+
+	after, err := change(tx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry := newHistoryEntry(ctx, action, before, after); len(entry.ChangedFields) > 0 {
+		if err := insertHistory(ctx, tx, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+const historyColumns = "id, person_id, action, before, after, changed_fields, request_id, user_id, created_at"
+
+func insertHistory(ctx context.Context, tx *sql.Tx, entry *models.PersonHistoryEntry) error {
+	before, err := marshalPerson(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalPerson(entry.After)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO people_history (person_id, action, before, after, changed_fields, request_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, entry.PersonID, entry.Action, before, after, pq.Array(entry.ChangedFields),
+		nullString(entry.RequestID), nullString(entry.UserID),
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func marshalPerson(p *models.Person) ([]byte, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+func unmarshalPerson(data []byte) (*models.Person, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var p models.Person
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func scanHistoryEntry(row rowScanner) (*models.PersonHistoryEntry, error) {
+	var entry models.PersonHistoryEntry
+	var before, after []byte
+	var requestID, userID sql.NullString
+	if err := row.Scan(
+		&entry.ID, &entry.PersonID, &entry.Action, &before, &after,
+		pq.Array(&entry.ChangedFields), &requestID, &userID, &entry.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if entry.Before, err = unmarshalPerson(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = unmarshalPerson(after); err != nil {
+		return nil, err
+	}
+
+	entry.RequestID = requestID.String
+	entry.UserID = userID.String
+	return &entry, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPerson(row rowScanner) (*models.Person, error) {
+	var p models.Person
+	var patronymic, gender, nationality sql.NullString
+	var age sql.NullInt64
+	var deletedAt sql.NullTime
+	var attributes []byte
+	if err := row.Scan(
+		&p.ID, &p.Name, &p.Surname, &patronymic,
+		&age, &gender, &nationality, &p.CreatedAt, &p.UpdatedAt, &p.EnrichmentStatus, &deletedAt, &p.Version,
+		&attributes,
+	); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	if err := json.Unmarshal(attributes, &p.Attributes); err != nil {
+		return nil, fmt.Errorf("invalid attributes of person %d: %w", p.ID, err)
+	}
+	if len(p.Attributes) == 0 {
+		p.Attributes = nil
+	}
+
+	p.Patronymic = patronymic.String
+	p.Age = int(age.Int64)
+	p.Gender = gender.String
+	p.Nationality = nationality.String
+	return &p, nil
+}
+
+// attributesJSON кодирует атрибуты для колонки JSONB; nil — пустой объект
+func attributesJSON(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return "{}"
+	}
+	data, _ := json.Marshal(attributes)
+	return string(data)
+}
+
+// nullString превращает пустую строку в NULL
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullInt превращает нулевое значение в NULL
+func nullInt(i int) *int {
+	if i == 0 {
+		return nil
+	}
+	return &i
+}
+
+func buildWhereClause(filter models.PersonFilter) (string, []interface{}) {
+	where := " WHERE 1=1"
+	if !filter.IncludeDeleted {
+		where += " AND deleted_at IS NULL"
+	}
+	var args []interface{}
+	argPos := 1
+
+	if filter.Name != "" {
+		where += " AND name ILIKE $" + strconv.Itoa(argPos)
+		args = append(args, "%"+filter.Name+"%")
+		argPos++
+	}
+	if filter.Surname != "" {
+		where += " AND surname ILIKE $" + strconv.Itoa(argPos)
+		args = append(args, "%"+filter.Surname+"%")
+		argPos++
+	}
+	if filter.AgeFrom != nil {
+		where += " AND age >= $" + strconv.Itoa(argPos)
+		args = append(args, *filter.AgeFrom)
+		argPos++
+	}
+	if filter.AgeTo != nil {
+		where += " AND age <= $" + strconv.Itoa(argPos)
+		args = append(args, *filter.AgeTo)
+		argPos++
+	}
+	if filter.Gender != "" {
+		where += " AND gender = $" + strconv.Itoa(argPos)
+		args = append(args, filter.Gender)
+		argPos++
+	}
+	if filter.Nationality != "" {
+		where += " AND nationality = $" + strconv.Itoa(argPos)
+		args = append(args, filter.Nationality)
+	}
+
+	return where, args
+}
+
+func buildFilterQuery(filter models.PersonFilter, page pageRequest) (string, []interface{}) {
+	where, args := buildWhereClause(filter)
+	query := "SELECT " + personColumns + " FROM people" + where
+	argPos := len(args) + 1
+
+	// При движении назад порядок сортировки инвертируется,
+	// а результат переворачивается в buildPage
+	desc := page.sort.desc != page.backward()
+	order, cmp := "ASC", ">"
+	if desc {
+		order, cmp = "DESC", "<"
+	}
+	expr := page.sort.column.expr
+
+	if page.cursor != nil {
+		query += " AND (" + expr + ", id) " + cmp +
+			" ($" + strconv.Itoa(argPos) + "::" + page.sort.column.cast + ", $" + strconv.Itoa(argPos+1) + ")"
+		args = append(args, page.cursor.Value, page.cursor.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY " + expr + " " + order + ", id " + order
+	query += " LIMIT $" + strconv.Itoa(argPos)
+	args = append(args, page.limit+1)
+	argPos++
+
+	if page.cursor == nil && page.offset > 0 {
+		query += " OFFSET $" + strconv.Itoa(argPos)
+		args = append(args, page.offset)
+	}
+
+	return query, args
+}
+
+func buildCountQuery(filter models.PersonFilter) (string, []interface{}) {
+	where, args := buildWhereClause(filter)
+	return "SELECT COUNT(*) FROM people" + where, args
+}
+
+var (
+	nullableColumns = map[string]bool{"patronymic": true, "gender": true, "age": true, "nationality": true}
+	// emptyColumnValues пустые значения очищаемых колонок; у gender (enum gender_type)
+	// пустой строки быть не может, а сравнение enum с '' завершилось бы ошибкой 22P02
+	emptyColumnValues = map[string]string{"patronymic": "''", "age": "0", "nationality": "''"}
+	testColumns       = map[string]bool{
+		"name": true, "surname": true, "patronymic": true, "gender": true, "age": true, "nationality": true,
+	}
+)
+
+func buildPartialUpdateQuery(id int, input models.UpdatePersonRequest) (string, []interface{}) {
+	query := "UPDATE people SET "
+	var args []interface{}
+	argPos := 1
+	fields := 0
+
+	if input.Name != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "name = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Name)
+		argPos++
+		fields++
+	}
+	if input.Surname != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "surname = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Surname)
+		argPos++
+		fields++
+	}
+	if input.Patronymic != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "patronymic = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Patronymic)
+		argPos++
+		fields++
+	}
+	if input.Age != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "age = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Age)
+		argPos++
+		fields++
+	}
+	if input.Gender != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "gender = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Gender)
+		argPos++
+		fields++
+	}
+	if input.Nationality != nil {
+		if fields > 0 {
+			query += ", "
+		}
+		query += "nationality = $" + strconv.Itoa(argPos)
+		args = append(args, *input.Nationality)
+		argPos++
+		fields++
+	}
+
+	for _, field := range input.Null {
+		if !nullableColumns[field] {
+			continue
+		}
+		if fields > 0 {
+			query += ", "
+		}
+		query += field + " = NULL"
+		fields++
+	}
+
+	if fields == 0 {
+		return "", nil
+	}
+
+	query += ", updated_at = NOW() WHERE id = $" + strconv.Itoa(argPos)
+	args = append(args, id)
+	argPos++
+
+	// Условия JSON Patch "test" проверяются в том же UPDATE: если они не выполнены, строка не изменится
+	for _, test := range input.Tests {
+		if !testColumns[test.Field] {
+			continue
+		}
+		column := test.Field
+		if empty, ok := emptyColumnValues[column]; ok {
+			// Пустое значение в строке считается NULL, как и в testValue
+			column = "NULLIF(" + column + ", " + empty + ")"
+		}
+		query += " AND " + column + " IS NOT DISTINCT FROM $" + strconv.Itoa(argPos)
+		args = append(args, testValue(test))
+		argPos++
+	}
+
+	query += " RETURNING " + personColumns
+
+	return query, args
+}
@@ -0,0 +1,114 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header заголовок, в котором идентификатор запроса принимается и возвращается
+const Header = "X-Request-ID"
+
+// maxLength ограничивает длину идентификатора, присланного клиентом
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewContext сохраняет идентификатор запроса в контексте
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает идентификатор запроса или пустую строку
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+type userIDKey struct{}
+
+// WithUserID сохраняет в контексте пользователя запроса для логов и истории изменений
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext возвращает пользователя запроса или пустую строку
+func UserIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// Middleware берёт X-Request-ID клиента или генерирует новый, кладёт его в контекст запроса
+// и возвращает в ответе. Подключается первым, чтобы идентификатор был во всех логах.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = Generate()
+		}
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), id))
+		c.Set("request_id", id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// Generate возвращает новый случайный идентификатор
+func Generate() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// valid пропускает только короткие идентификаторы из безопасных символов,
+// чтобы клиент не мог подложить в логи переводы строк или мегабайтные заголовки
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Transport передаёт идентификатор запроса из контекста во внешние запросы
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTripper не должен менять исходный запрос
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, id)
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,88 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, FromContext(c.Request.Context()))
+	})
+
+	cases := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"accepted", "req-42.a:b_c", true},
+		{"unsafe chars", "bad id\nInjected: 1", false},
+		{"too long", strings.Repeat("a", maxLength+1), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.incoming != "" {
+				req.Header.Set(Header, tc.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(Header)
+			if id == "" || id != w.Body.String() {
+				t.Fatalf("expected the same id in header and context, got %q and %q", id, w.Body.String())
+			}
+			if (id == tc.incoming) != tc.keep {
+				t.Errorf("incoming %q, got %q", tc.incoming, id)
+			}
+		})
+	}
+}
+
+func TestTransportForwardsRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	req, _ := http.NewRequestWithContext(NewContext(t.Context(), "req-1"), http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got != "req-1" {
+		t.Errorf("expected X-Request-ID to be forwarded, got %q", got)
+	}
+	if req.Header.Get(Header) != "" {
+		t.Error("transport must not modify the caller's request")
+	}
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	ctx := WithUserID(context.Background(), "alice")
+	if got := UserIDFromContext(ctx); got != "alice" {
+		t.Errorf("expected alice, got %q", got)
+	}
+	// Строковый ключ из другого пакета не путается с типизированным
+	//nolint:staticcheck // проверяем именно строковый ключ
+	ctx = context.WithValue(context.Background(), "user_id", "mallory")
+	if got := UserIDFromContext(ctx); got != "" {
+		t.Errorf("expected empty user id, got %q", got)
+	}
+	if got := UserIDFromContext(nil); got != "" { //nolint:staticcheck // nil-контекст допустим
+		t.Errorf("expected empty user id for nil context, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/health"
+	"go-people-api/log"
+)
+
+// shutdownStep этап остановки; этапы выполняются по порядку в пределах общего таймаута
+type shutdownStep struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// serve запускает сервер и ждёт SIGINT/SIGTERM. При остановке /readyz сразу начинает отвечать 503,
+// через SHUTDOWN_READINESS_DELAY сервер перестаёт принимать соединения и дожидается
+// текущих запросов, затем выполняются остальные этапы. На всё отводится SHUTDOWN_TIMEOUT.
+func serve(srv *http.Server, config config.ServerConfig, checker *health.Checker, steps []shutdownStep) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Logger.Fatal("Server failed: ", err)
+	case <-ctx.Done():
+	}
+	// Повторный сигнал завершает процесс сразу, не дожидаясь остановки
+	stop()
+
+	log.Logger.Info("Shutting down")
+	checker.SetShuttingDown()
+	// Даём балансировщику заметить 503 на /readyz, пока сервер ещё принимает запросы
+	time.Sleep(config.ShutdownReadinessDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	steps = append([]shutdownStep{{name: "http server", fn: func(ctx context.Context) error {
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			// Не уложились в таймаут: обрываем оставшиеся соединения, контексты запросов отменяются
+			_ = srv.Close()
+		}
+		return err
+	}}}, steps...)
+
+	for _, step := range steps {
+		start := time.Now()
+		if err := step.fn(shutdownCtx); err != nil {
+			log.Logger.WithError(err).Errorf("Failed to stop %s", step.name)
+			continue
+		}
+		log.Logger.Infof("Stopped %s in %s", step.name, time.Since(start).Round(time.Millisecond))
+	}
+	log.Logger.Info("Server stopped")
+}
+
+// waitStop оборачивает блокирующую остановку так, чтобы она не держала процесс дольше таймаута
+func waitStop(stop func()) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			stop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
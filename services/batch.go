@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	log "go-people-api/log"
+	"go-people-api/models"
+	"go-people-api/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// batchConcurrency ограничивает число одновременных запросов к провайдерам при пакетном обогащении
+const batchConcurrency = 8
+
+// BatchEnricher умеет обогащать несколько имён за раз.
+// Возвращает данные для каждого уникального имени и ошибки для имён, обогащённых не полностью.
+type BatchEnricher interface {
+	EnrichBatch(ctx context.Context, names []string) (map[string]*models.Person, map[string]error)
+}
+
+// EnrichBatch обогащает имена пакетными запросами (name[]=...) там, где провайдер их поддерживает.
+// Имена, совпадающие по ключу кэша, запрашиваются один раз.
+func (s *EnrichmentService) EnrichBatch(ctx context.Context, names []string) (map[string]*models.Person, map[string]error) {
+	unique := uniqueNames(names)
+	people := make(map[string]*models.Person, len(unique))
+	for _, name := range unique {
+		people[name] = &models.Person{}
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, batchConcurrency)
+		errs = make(map[string][]error)
+	)
+
+	for _, provider := range s.providers {
+		for _, chunk := range chunkNames(provider, unique) {
+			wg.Add(1)
+			go func(provider EnrichmentProvider, chunk []string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				partials, chunkErrs := s.fetchChunk(ctx, provider, chunk)
+
+				mu.Lock()
+				defer mu.Unlock()
+				for i, name := range chunk {
+					if chunkErrs[i] != nil {
+						errs[name] = append(errs[name], chunkErrs[i])
+						continue
+					}
+					copyFields(people[name], partials[i], provider.Fields())
+				}
+			}(provider, chunk)
+		}
+	}
+	wg.Wait()
+
+	result := make(map[string]error, len(errs))
+	for name, nameErrs := range errs {
+		log.WithContext(ctx).Warnf("Partial enrichment errors for %s: %v", name, nameErrs)
+		result[name] = fmt.Errorf("partial enrichment failure (%d errors)", len(nameErrs))
+	}
+	spreadResults(names, people, result)
+	return people, result
+}
+
+// fetchChunk запрашивает у провайдера данные для группы имён
+func (s *EnrichmentService) fetchChunk(ctx context.Context, provider EnrichmentProvider, chunk []string) ([]*models.Person, []error) {
+	partials := make([]*models.Person, len(chunk))
+	errs := make([]error, len(chunk))
+
+	bp, ok := provider.(BatchProvider)
+	if !ok || len(chunk) == 1 {
+		// Спаны создаёт fetchProvider для каждого имени
+		for i, name := range chunk {
+			partials[i], errs[i] = s.fetchProvider(ctx, provider, name)
+		}
+		return partials, errs
+	}
+
+	ctx, span := tracing.Start(ctx, "enrichment."+provider.Name()+".batch",
+		attribute.String("enrichment.provider", provider.Name()),
+		attribute.Int("enrichment.batch_size", len(chunk)),
+	)
+	defer span.End()
+
+	fail := func(err error) ([]*models.Person, []error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		for i := range errs {
+			errs[i] = err
+		}
+		return partials, errs
+	}
+
+	url := bp.BatchRequestURL(chunk)
+	if url == "" {
+		return fail(fmt.Errorf("%s API not configured", provider.Name()))
+	}
+
+	var res []map[string]interface{}
+	if err := s.fetchWithRetry(ctx, s.states[provider.Name()], url, &res); err != nil {
+		return fail(fmt.Errorf("%s API request failed: %w", provider.Name(), err))
+	}
+	if len(res) != len(chunk) {
+		return fail(fmt.Errorf("%s API returned %d results for %d names", provider.Name(), len(res), len(chunk)))
+	}
+
+	for i := range chunk {
+		partial := &models.Person{}
+		if err := provider.Parse(res[i], partial); err != nil {
+			errs[i] = fmt.Errorf("%s API: %w", provider.Name(), err)
+			continue
+		}
+		partials[i] = partial
+	}
+	return partials, errs
+}
+
+// chunkNames делит имена на группы по размеру пакета провайдера
+func chunkNames(provider EnrichmentProvider, names []string) [][]string {
+	size := 1
+	if bp, ok := provider.(BatchProvider); ok && bp.BatchSize() > 1 {
+		size = bp.BatchSize()
+	}
+
+	chunks := make([][]string, 0, (len(names)+size-1)/size)
+	for start := 0; start < len(names); start += size {
+		end := start + size
+		if end > len(names) {
+			end = len(names)
+		}
+		chunks = append(chunks, names[start:end])
+	}
+	return chunks
+}
+
+// uniqueNames оставляет по одному имени на ключ кэша в порядке первого появления
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		key := cacheKey(name)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// spreadResults копирует результат первого имени с тем же ключом кэша на все остальные варианты написания
+func spreadResults(names []string, people map[string]*models.Person, errs map[string]error) {
+	first := make(map[string]string, len(names))
+	for _, name := range names {
+		key := cacheKey(name)
+		origin, ok := first[key]
+		if !ok {
+			first[key] = name
+			continue
+		}
+		if origin == name {
+			continue
+		}
+		people[name] = people[origin]
+		if err, ok := errs[origin]; ok {
+			errs[name] = err
+		}
+	}
+}
+
+// enrichBatch использует пакетное обогащение, если оно поддерживается, иначе обогащает по одному имени
+func enrichBatch(ctx context.Context, enricher Enricher, names []string) (map[string]*models.Person, map[string]error) {
+	if be, ok := enricher.(BatchEnricher); ok {
+		return be.EnrichBatch(ctx, names)
+	}
+
+	people := make(map[string]*models.Person)
+	errs := make(map[string]error)
+	for _, name := range uniqueNames(names) {
+		person, err := enricher.Enrich(ctx, name)
+		people[name] = person
+		if err != nil {
+			errs[name] = err
+		}
+	}
+	spreadResults(names, people, errs)
+	return people, errs
+}
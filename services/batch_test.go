@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchAgeAPI отвечает как agify на name[]=...: массив в порядке имён, возраст равен длине имени
+func batchAgeAPI(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		names := r.URL.Query()["name[]"]
+		if len(names) == 0 {
+			names = r.URL.Query()["name"]
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": names[0], "age": len(names[0])})
+			return
+		}
+		res := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			res = append(res, map[string]interface{}{"name": name, "age": len(name)})
+		}
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			t.Errorf("could not encode mock response: %v", err)
+		}
+	}))
+}
+
+func TestEnrichmentService_EnrichBatch(t *testing.T) {
+	var calls atomic.Int32
+	server := batchAgeAPI(t, &calls)
+	defer server.Close()
+
+	provider, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(provider)
+
+	names := []string{"Ivan", "Anna", "Ivan", "Konstantin", "Olga", "Petr", "Maria", "Oleg", "Igor", "Nina", "Vera", "Lev"}
+	people, errs := service.EnrichBatch(context.Background(), names)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(people) != 11 {
+		t.Fatalf("expected 11 unique names, got %d", len(people))
+	}
+	if people["Konstantin"].Age != 10 || people["Lev"].Age != 3 {
+		t.Errorf("unexpected ages: %+v, %+v", people["Konstantin"], people["Lev"])
+	}
+	// 11 имён при размере пакета 10: один пакетный запрос и один одиночный
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 API calls, got %d", n)
+	}
+}
+
+func TestEnrichmentService_EnrichBatch_LengthMismatch(t *testing.T) {
+	server := mockAPI(t, []map[string]interface{}{{"age": 30}})
+	defer server.Close()
+
+	provider, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(provider)
+	service.SetProviderPolicy("age", fastPolicy(1, 5))
+
+	_, errs := service.EnrichBatch(context.Background(), []string{"Ivan", "Anna"})
+	if errs["Ivan"] == nil || errs["Anna"] == nil {
+		t.Errorf("expected errors for every name in the chunk, got %v", errs)
+	}
+}
+
+func TestCachingEnricher_EnrichBatch(t *testing.T) {
+	var calls atomic.Int32
+	server := batchAgeAPI(t, &calls)
+	defer server.Close()
+
+	provider, _ := NewProvider("age", "agify", server.URL)
+	cache := NewCachingEnricher(NewEnrichmentServiceWithProviders(provider), time.Minute, time.Minute, NewMemoryCacheStore(10))
+	ctx := context.Background()
+
+	if _, err := cache.Enrich(ctx, "Ivan"); err != nil {
+		t.Fatal(err)
+	}
+	people, errs := cache.EnrichBatch(ctx, []string{"Ivan", "Anna", "Olga"})
+	if len(errs) != 0 || people["Ivan"].Age != 4 || people["Olga"].Age != 4 {
+		t.Fatalf("unexpected result: %+v, %v", people, errs)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 API calls, got %d", n)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestEnrichBatch_DeduplicatesByCacheKey(t *testing.T) {
+	var calls atomic.Int32
+	server := batchAgeAPI(t, &calls)
+	defer server.Close()
+
+	provider, _ := NewProvider("age", "agify", server.URL)
+	cache := NewCachingEnricher(NewEnrichmentServiceWithProviders(provider), time.Minute, time.Minute, NewMemoryCacheStore(10))
+	ctx := context.Background()
+
+	names := []string{"Anna", "anna ", "ANNA", "Ivan"}
+	people, errs := cache.EnrichBatch(ctx, names)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for _, name := range names {
+		if people[name] == nil || people[name].Age != 4 {
+			t.Errorf("expected result for %q, got %+v", name, people[name])
+		}
+	}
+	// Варианты написания Anna попадают в один пакетный запрос как одно имя
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 API call, got %d", n)
+	}
+	if stats := cache.Stats(); stats.Misses != 2 {
+		t.Errorf("expected 2 cache misses, got %+v", stats)
+	}
+
+	people, _ = cache.EnrichBatch(ctx, []string{" IVAN", "ivan"})
+	if people[" IVAN"] == nil || people["ivan"] == nil || calls.Load() != 1 {
+		t.Errorf("expected cached results for both spellings, got %+v after %d calls", people, calls.Load())
+	}
+}
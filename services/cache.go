@@ -0,0 +1,302 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "go-people-api/log"
+	"go-people-api/models"
+)
+
+// Enricher то, что умеет обогащать данные по имени (EnrichmentService или обёртка над ним)
+type Enricher interface {
+	Enrich(ctx context.Context, name string) (*models.Person, error)
+}
+
+// CacheEntry сохранённый результат обогащения.
+// Непустой Err означает, что обогащение завершилось ошибкой (негативный кэш).
+type CacheEntry struct {
+	Person    models.Person `json:"person"`
+	Err       string        `json:"error,omitempty"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// CacheStore хранилище записей кэша обогащения
+type CacheStore interface {
+	Get(ctx context.Context, key string) (CacheEntry, bool, error)
+	Set(ctx context.Context, key string, entry CacheEntry) error
+	Delete(ctx context.Context, key string) error
+	Clear(ctx context.Context) error
+}
+
+// CachingEnricher кэширует результаты обогащения по имени.
+// Хранилища опрашиваются по порядку, найденная запись копируется в предыдущие уровни.
+type CachingEnricher struct {
+	next        Enricher
+	stores      []CacheStore
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+}
+
+func NewCachingEnricher(next Enricher, ttl, negativeTTL time.Duration, stores ...CacheStore) *CachingEnricher {
+	return &CachingEnricher{
+		next:        next,
+		stores:      stores,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func cacheKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (c *CachingEnricher) Enrich(ctx context.Context, name string) (*models.Person, error) {
+	return c.enrich(ctx, name, false)
+}
+
+// RetryEnricher возвращает обёртку, для которой закэшированные ошибки считаются промахом:
+// повторные попытки фоновых задач должны действительно обращаться к API
+func (c *CachingEnricher) RetryEnricher() Enricher {
+	return retryEnricher{cache: c}
+}
+
+type retryEnricher struct {
+	cache *CachingEnricher
+}
+
+func (r retryEnricher) Enrich(ctx context.Context, name string) (*models.Person, error) {
+	return r.cache.enrich(ctx, name, true)
+}
+
+func (c *CachingEnricher) enrich(ctx context.Context, name string, skipNegative bool) (*models.Person, error) {
+	key := cacheKey(name)
+
+	if entry, ok := c.lookup(ctx, key, skipNegative); ok {
+		person := entry.Person
+		if entry.Err != "" {
+			c.negativeHits.Add(1)
+			return &person, errors.New(entry.Err)
+		}
+		c.hits.Add(1)
+		return &person, nil
+	}
+	c.misses.Add(1)
+
+	person, err := c.next.Enrich(ctx, name)
+	if ctx.Err() != nil {
+		// Запрос отменён клиентом — результат не показателен
+		return person, err
+	}
+
+	c.remember(ctx, key, person, err)
+	return person, err
+}
+
+// EnrichBatch отдаёт закэшированные имена из кэша, а промахи обогащает одним пакетом
+func (c *CachingEnricher) EnrichBatch(ctx context.Context, names []string) (map[string]*models.Person, map[string]error) {
+	people := make(map[string]*models.Person)
+	errs := make(map[string]error)
+
+	var missing []string
+	for _, name := range uniqueNames(names) {
+		entry, ok := c.lookup(ctx, cacheKey(name), false)
+		if !ok {
+			c.misses.Add(1)
+			missing = append(missing, name)
+			continue
+		}
+		person := entry.Person
+		people[name] = &person
+		if entry.Err != "" {
+			c.negativeHits.Add(1)
+			errs[name] = errors.New(entry.Err)
+		} else {
+			c.hits.Add(1)
+		}
+	}
+
+	if len(missing) == 0 {
+		spreadResults(names, people, errs)
+		return people, errs
+	}
+
+	fetched, fetchErrs := enrichBatch(ctx, c.next, missing)
+	for _, name := range missing {
+		people[name] = fetched[name]
+		if err := fetchErrs[name]; err != nil {
+			errs[name] = err
+		}
+		if ctx.Err() == nil {
+			c.remember(ctx, cacheKey(name), fetched[name], fetchErrs[name])
+		}
+	}
+	spreadResults(names, people, errs)
+	return people, errs
+}
+
+// remember сохраняет результат обогащения во все хранилища с учётом TTL
+func (c *CachingEnricher) remember(ctx context.Context, key string, person *models.Person, err error) {
+	entry := CacheEntry{ExpiresAt: time.Now().Add(c.ttl)}
+	if person != nil {
+		entry.Person = *person
+	}
+	if err != nil {
+		entry.Err = err.Error()
+		entry.ExpiresAt = time.Now().Add(c.negativeTTL)
+	}
+	if c.ttl > 0 && (err == nil || c.negativeTTL > 0) {
+		c.store(ctx, key, entry, len(c.stores))
+	}
+}
+
+func (c *CachingEnricher) lookup(ctx context.Context, key string, skipNegative bool) (CacheEntry, bool) {
+	for i, store := range c.stores {
+		entry, ok, err := store.Get(ctx, key)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Enrichment cache lookup failed")
+			continue
+		}
+		if !ok || (skipNegative && entry.Err != "") {
+			continue
+		}
+		if time.Now().After(entry.ExpiresAt) {
+			_ = store.Delete(ctx, key)
+			continue
+		}
+		c.store(ctx, key, entry, i)
+		return entry, true
+	}
+	return CacheEntry{}, false
+}
+
+// store записывает запись в первые n хранилищ
+func (c *CachingEnricher) store(ctx context.Context, key string, entry CacheEntry, n int) {
+	for _, store := range c.stores[:n] {
+		if err := store.Set(ctx, key, entry); err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Enrichment cache write failed")
+		}
+	}
+}
+
+// Purge удаляет запись для имени, а при пустом имени очищает кэш целиком
+func (c *CachingEnricher) Purge(ctx context.Context, name string) error {
+	key := cacheKey(name)
+	for _, store := range c.stores {
+		var err error
+		if key == "" {
+			err = store.Clear(ctx)
+		} else {
+			err = store.Delete(ctx, key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CachingEnricher) Stats() models.CacheStats {
+	stats := models.CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	for _, store := range c.stores {
+		if m, ok := store.(*MemoryCacheStore); ok {
+			stats.Entries = m.Len()
+			break
+		}
+	}
+	return stats
+}
+
+// MemoryCacheStore LRU-кэш ограниченного размера
+type MemoryCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (m *MemoryCacheStore) Get(_ context.Context, key string) (CacheEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return CacheEntry{}, false, nil
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true, nil
+}
+
+func (m *MemoryCacheStore) Set(_ context.Context, key string, entry CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+func (m *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.order.Remove(el)
+		delete(m.items, key)
+	}
+	return nil
+}
+
+func (m *MemoryCacheStore) Clear(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.order.Init()
+	return nil
+}
+
+func (m *MemoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
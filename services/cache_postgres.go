@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// PostgresCacheStore хранит кэш обогащения в таблице enrichment_cache,
+// чтобы он переживал перезапуски и был общим для нескольких экземпляров
+type PostgresCacheStore struct {
+	db *sql.DB
+}
+
+func NewPostgresCacheStore(db *sql.DB) *PostgresCacheStore {
+	return &PostgresCacheStore{db: db}
+}
+
+func (p *PostgresCacheStore) Get(ctx context.Context, key string) (CacheEntry, bool, error) {
+	var entry CacheEntry
+	var person []byte
+	var errText sql.NullString
+
+	err := p.db.QueryRowContext(ctx,
+		"SELECT person, error, expires_at FROM enrichment_cache WHERE name = $1 AND expires_at > NOW()",
+		key,
+	).Scan(&person, &errText, &entry.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+
+	if err := json.Unmarshal(person, &entry.Person); err != nil {
+		return CacheEntry{}, false, err
+	}
+	entry.Err = errText.String
+	return entry, true, nil
+}
+
+func (p *PostgresCacheStore) Set(ctx context.Context, key string, entry CacheEntry) error {
+	person, err := json.Marshal(entry.Person)
+	if err != nil {
+		return err
+	}
+
+	var errText *string
+	if entry.Err != "" {
+		errText = &entry.Err
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO enrichment_cache (name, person, error, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET person = EXCLUDED.person, error = EXCLUDED.error, expires_at = EXCLUDED.expires_at
+	`, key, person, errText, entry.ExpiresAt)
+	return err
+}
+
+func (p *PostgresCacheStore) Delete(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM enrichment_cache WHERE name = $1", key)
+	return err
+}
+
+func (p *PostgresCacheStore) Clear(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM enrichment_cache")
+	return err
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-people-api/models"
+)
+
+type countingEnricher struct {
+	calls int
+	err   error
+}
+
+func (e *countingEnricher) Enrich(_ context.Context, name string) (*models.Person, error) {
+	e.calls++
+	return &models.Person{Age: len(name)}, e.err
+}
+
+func TestCachingEnricher_HitAndMiss(t *testing.T) {
+	next := &countingEnricher{}
+	cache := NewCachingEnricher(next, time.Minute, time.Minute, NewMemoryCacheStore(10))
+	ctx := context.Background()
+
+	for _, name := range []string{"Ivan", "ivan ", "IVAN"} {
+		person, err := cache.Enrich(ctx, name)
+		if err != nil || person.Age != 4 {
+			t.Fatalf("unexpected result for %q: %+v, %v", name, person, err)
+		}
+	}
+
+	if next.calls != 1 {
+		t.Errorf("expected 1 upstream call, got %d", next.calls)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachingEnricher_NegativeCaching(t *testing.T) {
+	next := &countingEnricher{err: errors.New("partial enrichment failure")}
+	cache := NewCachingEnricher(next, time.Minute, 20*time.Millisecond, NewMemoryCacheStore(10))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Enrich(ctx, "Anna"); err == nil {
+			t.Fatal("expected cached error")
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("expected failure to be cached, got %d upstream calls", next.calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	_, _ = cache.Enrich(ctx, "Anna")
+	if next.calls != 2 {
+		t.Errorf("expected negative entry to expire, got %d upstream calls", next.calls)
+	}
+	if stats := cache.Stats(); stats.NegativeHits != 1 {
+		t.Errorf("expected 1 negative hit, got %+v", stats)
+	}
+}
+
+func TestCachingEnricher_TieredAndPurge(t *testing.T) {
+	next := &countingEnricher{}
+	l1, l2 := NewMemoryCacheStore(1), NewMemoryCacheStore(10)
+	cache := NewCachingEnricher(next, time.Minute, time.Minute, l1, l2)
+	ctx := context.Background()
+
+	_, _ = cache.Enrich(ctx, "Ivan")
+	_, _ = cache.Enrich(ctx, "Olga") // вытесняет Ivan из l1
+	if _, ok, _ := l1.Get(ctx, "ivan"); ok {
+		t.Fatal("expected LRU eviction from first level")
+	}
+
+	_, _ = cache.Enrich(ctx, "Ivan")
+	if next.calls != 2 {
+		t.Errorf("expected second level hit, got %d upstream calls", next.calls)
+	}
+	if _, ok, _ := l1.Get(ctx, "ivan"); !ok {
+		t.Error("expected entry to be promoted to first level")
+	}
+
+	if err := cache.Purge(ctx, "Ivan"); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = cache.Enrich(ctx, "Ivan")
+	if next.calls != 3 {
+		t.Errorf("expected purge to force upstream call, got %d", next.calls)
+	}
+
+	_ = cache.Purge(ctx, "")
+	if l2.Len() != 0 {
+		t.Errorf("expected empty cache after full purge, got %d entries", l2.Len())
+	}
+}
@@ -3,215 +3,226 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	log "go-people-api/log"
 	"go-people-api/models"
+	"go-people-api/requestid"
+	"go-people-api/tracing"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type EnrichmentService struct {
-	client         *http.Client
-	ageAPI         string
-	genderAPI      string
-	nationalityAPI string
+	client    *http.Client
+	providers []EnrichmentProvider
+	states    map[string]*providerState
+}
+
+// providerState политика, автомат и квота отдельного провайдера
+type providerState struct {
+	name    string
+	policy  ProviderPolicy
+	breaker *CircuitBreaker
+	quota   *QuotaTracker
 }
 
+// NewEnrichmentService создаёт сервис со стандартными провайдерами agify/genderize/nationalize
 func NewEnrichmentService(ageAPI, genderAPI, nationalityAPI string) *EnrichmentService {
+	age, _ := NewProvider("age", "agify", ageAPI)
+	gender, _ := NewProvider("gender", "genderize", genderAPI)
+	nationality, _ := NewProvider("nationality", "nationalize", nationalityAPI)
+	return NewEnrichmentServiceWithProviders(age, gender, nationality)
+}
+
+func NewEnrichmentServiceWithProviders(providers ...EnrichmentProvider) *EnrichmentService {
+	states := make(map[string]*providerState, len(providers))
+	for _, p := range providers {
+		states[p.Name()] = &providerState{
+			name:    p.Name(),
+			policy:  DefaultProviderPolicy,
+			breaker: NewCircuitBreaker(DefaultProviderPolicy.Breaker),
+			quota:   NewQuotaTracker(p.Name(), DefaultQuotaThresholds),
+		}
+	}
+
 	return &EnrichmentService{
 		client: &http.Client{
 			Timeout: 3 * time.Second,
-			Transport: &http.Transport{
+			// otelhttp создаёт спан на каждый запрос и передаёт провайдеру traceparent,
+			// requestid.Transport — X-Request-ID входящего запроса
+			Transport: otelhttp.NewTransport(requestid.NewTransport(&http.Transport{
 				MaxIdleConns:        10,
 				IdleConnTimeout:     30 * time.Second,
 				DisableCompression:  false,
 				DisableKeepAlives:   false,
 				MaxIdleConnsPerHost: 5,
-			},
+			})),
 		},
-		ageAPI:         ageAPI,
-		genderAPI:      genderAPI,
-		nationalityAPI: nationalityAPI,
+		providers: providers,
+		states:    states,
 	}
 }
 
-func (s *EnrichmentService) Enrich(ctx context.Context, name string) (*models.Person, error) {
-	logger := log.WithContext(ctx)
-	logger.Infof("Starting enrichment for: %s", name)
-
-	person := &models.Person{}
-	errChan := make(chan error, 3)
-	resultChan := make(chan struct {
-		field string
-		value interface{}
-	}, 3)
-
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-
-	go s.fetchAge(ctx, name, resultChan, errChan)
-	go s.fetchGender(ctx, name, resultChan, errChan)
-	go s.fetchNationality(ctx, name, resultChan, errChan)
+// Close закрывает простаивающие соединения с провайдерами; вызывается при остановке,
+// когда новых запросов к сервису уже не будет
+func (s *EnrichmentService) Close() {
+	s.client.CloseIdleConnections()
+}
 
-	var errs []error
-	for i := 0; i < 3; i++ {
-		select {
-		case res := <-resultChan:
-			switch res.field {
-			case "age":
-				if age, ok := res.value.(int); ok {
-					person.Age = age
-				}
-			case "gender":
-				if gender, ok := res.value.(string); ok {
-					person.Gender = gender
-				}
-			case "nationality":
-				if nationality, ok := res.value.(string); ok {
-					person.Nationality = nationality
-				}
-			}
-		case err := <-errChan:
-			errs = append(errs, err)
-		}
+// SetProviderPolicy задаёт политику повторов и автомата для провайдера.
+// Вызывается при настройке сервиса, до начала обработки запросов.
+func (s *EnrichmentService) SetProviderPolicy(name string, policy ProviderPolicy) error {
+	state, ok := s.states[name]
+	if !ok {
+		return fmt.Errorf("unknown provider %q", name)
 	}
-
-	if len(errs) > 0 {
-		logger.Warnf("Partial enrichment errors: %v", errs)
-		return person, fmt.Errorf("partial enrichment failure (%d errors)", len(errs))
+	state.quota.SetBackoff(policy.QuotaBackoff)
+	s.states[name] = &providerState{
+		name:    name,
+		policy:  policy,
+		breaker: NewCircuitBreaker(policy.Breaker),
+		quota:   state.quota,
 	}
-
-	logger.Infof("Successfully enriched: %+v", person)
-	return person, nil
+	return nil
 }
 
-func (s *EnrichmentService) fetchAge(ctx context.Context, name string, resultChan chan<- struct {
-	field string
-	value interface{}
-}, errChan chan<- error) {
-	if s.ageAPI == "" {
-		errChan <- errors.New("age API not configured")
-		return
+// SetQuotaThresholds задаёт доли оставшейся квоты, при которых пишутся предупреждения.
+// Вызывается при настройке сервиса, до начала обработки запросов.
+func (s *EnrichmentService) SetQuotaThresholds(thresholds []float64) {
+	for name, state := range s.states {
+		state.quota = NewQuotaTracker(name, thresholds)
+		state.quota.SetBackoff(state.policy.QuotaBackoff)
 	}
+}
 
-	res, err := s.fetchAPI(ctx, s.ageAPI+"?name="+name)
-	if err != nil {
-		errChan <- fmt.Errorf("age API request failed: %w", err)
-		return
+// ProviderQuotas состояние квот провайдеров для административного API
+func (s *EnrichmentService) ProviderQuotas() []models.QuotaStatus {
+	quotas := make([]models.QuotaStatus, 0, len(s.providers))
+	for _, p := range s.providers {
+		quotas = append(quotas, s.states[p.Name()].quota.Status())
 	}
+	return quotas
+}
 
-	age, ok := res["age"]
-	if !ok {
-		errChan <- errors.New("age field missing in response")
-		return
+// ProviderStatuses состояние провайдеров для административного API
+func (s *EnrichmentService) ProviderStatuses() []models.ProviderStatus {
+	statuses := make([]models.ProviderStatus, 0, len(s.providers))
+	for _, p := range s.providers {
+		state, failures, openedAt := s.states[p.Name()].breaker.State()
+		status := models.ProviderStatus{
+			Name:                p.Name(),
+			Fields:              p.Fields(),
+			Configured:          p.RequestURL("") != "",
+			Breaker:             string(state),
+			ConsecutiveFailures: failures,
+		}
+		if state != BreakerClosed {
+			status.OpenedAt = &openedAt
+		}
+		statuses = append(statuses, status)
 	}
+	return statuses
+}
 
-	ageFloat, ok := age.(float64)
-	if !ok {
-		errChan <- fmt.Errorf("invalid age type: %T", age)
-		return
-	}
+// Providers возвращает список подключённых провайдеров
+func (s *EnrichmentService) Providers() []EnrichmentProvider {
+	return s.providers
+}
 
-	resultChan <- struct {
-		field string
-		value interface{}
-	}{"age", int(ageFloat)}
+type providerResult struct {
+	provider EnrichmentProvider
+	person   *models.Person
+	err      error
 }
 
-func (s *EnrichmentService) fetchGender(ctx context.Context, name string, resultChan chan<- struct {
-	field string
-	value interface{}
-}, errChan chan<- error) {
-	if s.genderAPI == "" {
-		errChan <- errors.New("gender API not configured")
-		return
-	}
+func (s *EnrichmentService) Enrich(ctx context.Context, name string) (_ *models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "enrichment.enrich")
+	defer func() { tracing.End(span, err) }()
 
-	res, err := s.fetchAPI(ctx, s.genderAPI+"?name="+name)
-	if err != nil {
-		errChan <- fmt.Errorf("gender API request failed: %w", err)
-		return
+	logger := log.WithContext(ctx)
+	logger.Infof("Starting enrichment for: %s", name)
+
+	person := &models.Person{}
+	resultChan := make(chan providerResult, len(s.providers))
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	for _, provider := range s.providers {
+		go func(provider EnrichmentProvider) {
+			partial, err := s.fetchProvider(ctx, provider, name)
+			resultChan <- providerResult{provider: provider, person: partial, err: err}
+		}(provider)
 	}
 
-	gender, ok := res["gender"]
-	if !ok {
-		errChan <- errors.New("gender field missing in response")
-		return
+	var errs []error
+	for range s.providers {
+		res := <-resultChan
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		copyFields(person, res.person, res.provider.Fields())
 	}
 
-	genderStr, ok := gender.(string)
-	if !ok {
-		errChan <- fmt.Errorf("invalid gender type: %T", gender)
-		return
+	if len(errs) > 0 {
+		logger.Warnf("Partial enrichment errors: %v", errs)
+		return person, fmt.Errorf("partial enrichment failure (%d errors)", len(errs))
 	}
 
-	resultChan <- struct {
-		field string
-		value interface{}
-	}{"gender", genderStr}
+	logger.Infof("Successfully enriched: %+v", person)
+	return person, nil
 }
 
-func (s *EnrichmentService) fetchNationality(ctx context.Context, name string, resultChan chan<- struct {
-	field string
-	value interface{}
-}, errChan chan<- error) {
-	if s.nationalityAPI == "" {
-		errChan <- errors.New("nationality API not configured")
-		return
-	}
-
-	res, err := s.fetchAPI(ctx, s.nationalityAPI+"?name="+name)
-	if err != nil {
-		errChan <- fmt.Errorf("nationality API request failed: %w", err)
-		return
-	}
+// fetchProvider запрашивает одного провайдера; спан охватывает все попытки,
+// а каждый HTTP-запрос получает дочерний спан от otelhttp
+func (s *EnrichmentService) fetchProvider(ctx context.Context, provider EnrichmentProvider, name string) (_ *models.Person, err error) {
+	ctx, span := tracing.Start(ctx, "enrichment."+provider.Name(),
+		attribute.String("enrichment.provider", provider.Name()),
+	)
+	defer func() { tracing.End(span, err) }()
 
-	countries, ok := res["country"].([]interface{})
-	if !ok || len(countries) == 0 {
-		errChan <- errors.New("no country data in response")
-		return
+	url := provider.RequestURL(name)
+	if url == "" {
+		return nil, fmt.Errorf("%s API not configured", provider.Name())
 	}
 
-	country, ok := countries[0].(map[string]interface{})
-	if !ok {
-		errChan <- errors.New("invalid country format in response")
-		return
+	var res map[string]interface{}
+	if err := s.fetchWithRetry(ctx, s.states[provider.Name()], url, &res); err != nil {
+		return nil, fmt.Errorf("%s API request failed: %w", provider.Name(), err)
 	}
 
-	id, ok := country["country_id"].(string)
-	if !ok {
-		errChan <- errors.New("invalid country_id format in response")
-		return
+	partial := &models.Person{}
+	if err := provider.Parse(res, partial); err != nil {
+		return nil, fmt.Errorf("%s API: %w", provider.Name(), err)
 	}
-
-	resultChan <- struct {
-		field string
-		value interface{}
-	}{"nationality", id}
+	return partial, nil
 }
 
-func (s *EnrichmentService) fetchAPI(ctx context.Context, url string) (map[string]interface{}, error) {
+// fetchAPI выполняет запрос и передаёт заголовки квоты в quota
+func (s *EnrichmentService) fetchAPI(ctx context.Context, quota *QuotaTracker, url string, out interface{}) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
+	quota.Observe(resp)
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status: %d", resp.StatusCode)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+		return &apiError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
-	return result, nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
@@ -3,10 +3,19 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	log "go-people-api/log"
+	"go-people-api/models"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func mockAPI(t *testing.T, response interface{}) *httptest.Server {
@@ -95,3 +104,125 @@ func TestEnrichmentService_Enrich_Success(t *testing.T) {
 		t.Errorf("expected nationality 'US', got %s", enriched.Nationality)
 	}
 }
+
+func TestEnrichmentService_CustomProvider(t *testing.T) {
+	err := RegisterProviderKind("ethnicity-test", ProviderKind{
+		Fields: []string{"attributes.ethnicity"},
+		Parse: func(body map[string]interface{}, person *models.Person) error {
+			person.Attributes = map[string]string{"ethnicity": body["ethnicity"].(string)}
+			person.Age = 99 // не объявлено в Fields и не должно попасть в результат
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register provider kind: %v", err)
+	}
+
+	server := mockAPI(t, map[string]interface{}{"ethnicity": "slavic"})
+	defer server.Close()
+
+	providers, err := ParseProviders("ethnicity=ethnicity-test:"+server.URL, nil)
+	if err != nil {
+		t.Fatalf("parse providers: %v", err)
+	}
+
+	service := NewEnrichmentServiceWithProviders(providers...)
+	person, err := service.Enrich(context.Background(), "Ivan")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if person.Attributes["ethnicity"] != "slavic" {
+		t.Errorf("expected ethnicity 'slavic', got %v", person.Attributes)
+	}
+	if person.Age != 0 {
+		t.Errorf("expected undeclared age to be ignored, got %d", person.Age)
+	}
+}
+
+func TestEnrichmentService_ConfiguredProviderKind(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"result": {"origin": "Greek", "locales": [{"code": "ru_RU"}], "score": 0.9}}`))
+	}))
+	defer server.Close()
+
+	kinds, err := ParseProviderKinds("origin=result.origin:attributes.name_origin;result.locales.0.code:attributes.locale;result.score:attributes.score")
+	if err != nil {
+		t.Fatalf("parse provider kinds: %v", err)
+	}
+	providers, err := ParseProviders("origin:"+server.URL+"/v1/names/{name}", kinds)
+	if err != nil {
+		t.Fatalf("parse providers: %v", err)
+	}
+
+	person, err := NewEnrichmentServiceWithProviders(providers...).Enrich(context.Background(), "Ivan")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := map[string]string{"name_origin": "Greek", "locale": "ru_RU", "score": "0.9"}
+	if fmt.Sprint(person.Attributes) != fmt.Sprint(want) || path != "/v1/names/Ivan" {
+		t.Errorf("unexpected attributes %v for %s", person.Attributes, path)
+	}
+}
+
+func TestParseProviderKinds_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"origin",
+		"origin=result.origin",
+		"origin=result.origin:patronymic",
+		"origin=result.origin:attributes.Bad-Key",
+		"agify=age:age",
+		"a=x:attributes.x,a=y:attributes.y",
+	} {
+		if _, err := ParseProviderKinds(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+	// Отчество вводит пользователь, провайдеры его не заполняют
+	if err := RegisterProviderKind("patronymic-test", ProviderKind{Fields: []string{"patronymic"}}); err == nil {
+		t.Error("patronymic must not be enrichable")
+	}
+}
+
+func TestParseProviders_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "agify", "unknown:http://x", "a=agify:http://x,a=genderize:http://y"} {
+		if _, err := ParseProviders(spec, nil); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestEnrichmentService_TracesProviderCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"age": 30}`))
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	if _, err := NewEnrichmentServiceWithProviders(age).Enrich(context.Background(), "Ivan"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, provider := spans["enrichment.enrich"], spans["enrichment.age"]
+	if root == nil || provider == nil {
+		t.Fatalf("expected enrich and provider spans, got %v", spans)
+	}
+	if provider.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Error("provider span must be a child of the enrich span")
+	}
+	if !strings.Contains(traceparent, root.SpanContext().TraceID().String()) {
+		t.Errorf("expected traceparent with trace id %s, got %q", root.SpanContext().TraceID(), traceparent)
+	}
+}
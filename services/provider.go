@@ -0,0 +1,419 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-people-api/models"
+)
+
+// EnrichmentProvider описывает внешний источник данных о человеке по имени
+type EnrichmentProvider interface {
+	// Name уникальное имя провайдера (используется в логах и ошибках)
+	Name() string
+	// Fields поля models.Person, которые заполняет провайдер
+	Fields() []string
+	// RequestURL адрес запроса для имени; пустая строка — провайдер не настроен
+	RequestURL(name string) string
+	// Parse разбирает JSON-ответ и заполняет поля person
+	Parse(body map[string]interface{}, person *models.Person) error
+}
+
+// BatchProvider провайдер, умеющий обогащать несколько имён одним запросом (name[]=...)
+type BatchProvider interface {
+	EnrichmentProvider
+	// BatchSize максимальное число имён в запросе; 0 или 1 — пакетные запросы не поддерживаются
+	BatchSize() int
+	// BatchRequestURL адрес запроса для нескольких имён; ответ — JSON-массив в том же порядке
+	BatchRequestURL(names []string) string
+}
+
+// ResponseParser разбирает ответ API конкретного формата
+type ResponseParser func(body map[string]interface{}, person *models.Person) error
+
+// AttributePrefix префикс полей провайдера, которые попадают в models.Person.Attributes:
+// "attributes.ethnicity" заполняет атрибут ethnicity
+const AttributePrefix = "attributes."
+
+// ProviderKind описывает формат API: какие поля он заполняет и как разбирать ответ
+type ProviderKind struct {
+	Fields    []string
+	Parse     ResponseParser
+	BatchSize int
+}
+
+var (
+	providerKindsMu sync.RWMutex
+	providerKinds   = map[string]ProviderKind{
+		"agify":       {Fields: []string{"age"}, Parse: parseAgify, BatchSize: 10},
+		"genderize":   {Fields: []string{"gender"}, Parse: parseGenderize, BatchSize: 10},
+		"nationalize": {Fields: []string{"nationality"}, Parse: parseNationalize, BatchSize: 10},
+	}
+)
+
+// RegisterProviderKind добавляет новый формат API в реестр
+func RegisterProviderKind(kind string, pk ProviderKind) error {
+	for _, field := range pk.Fields {
+		if !isEnrichableField(field) {
+			return fmt.Errorf("unknown person field %q", field)
+		}
+	}
+
+	providerKindsMu.Lock()
+	defer providerKindsMu.Unlock()
+	providerKinds[kind] = pk
+	return nil
+}
+
+// ProviderKinds возвращает имена зарегистрированных форматов
+func ProviderKinds() []string {
+	providerKindsMu.RLock()
+	defer providerKindsMu.RUnlock()
+
+	kinds := make([]string, 0, len(providerKinds))
+	for kind := range providerKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// NewProvider создаёт провайдера зарегистрированного формата
+func NewProvider(name, kind, endpoint string) (EnrichmentProvider, error) {
+	providerKindsMu.RLock()
+	pk, ok := providerKinds[kind]
+	providerKindsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider kind %q", kind)
+	}
+	return newAPIProvider(name, pk, endpoint), nil
+}
+
+// ParseProviderKinds разбирает форматы API, объявленные в конфигурации, из строки вида
+// "origin=data.origin:attributes.origin;data.locale:attributes.locale,ethnic=ethnicity:attributes.ethnicity".
+// Для каждого формата через ";" перечисляются пары "путь в JSON:поле"; путь — ключи через точку,
+// число выбирает элемент массива ("country.0.country_id"). Пакетные запросы такие форматы не поддерживают.
+func ParseProviderKinds(spec string) (map[string]ProviderKind, error) {
+	kinds := make(map[string]ProviderKind)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kind, mapping, ok := strings.Cut(item, "=")
+		if !ok || kind == "" || mapping == "" {
+			return nil, fmt.Errorf("invalid provider kind %q: expected kind=path:field;...", item)
+		}
+		if _, ok := kinds[kind]; ok {
+			return nil, fmt.Errorf("duplicate provider kind %q", kind)
+		}
+		providerKindsMu.RLock()
+		_, builtin := providerKinds[kind]
+		providerKindsMu.RUnlock()
+		if builtin {
+			return nil, fmt.Errorf("provider kind %q is already registered", kind)
+		}
+
+		var paths []jsonPath
+		var fields []string
+		for _, pair := range strings.Split(mapping, ";") {
+			path, field, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok || path == "" {
+				return nil, fmt.Errorf("invalid mapping %q in provider kind %q: expected path:field", pair, kind)
+			}
+			if !isEnrichableField(field) || slices.Contains(fields, field) {
+				return nil, fmt.Errorf("invalid field %q in provider kind %q", field, kind)
+			}
+			paths = append(paths, jsonPath{path: path, field: field})
+			fields = append(fields, field)
+		}
+		kinds[kind] = ProviderKind{Fields: fields, Parse: jsonPathParser(paths)}
+	}
+	return kinds, nil
+}
+
+func newAPIProvider(name string, pk ProviderKind, endpoint string) *apiProvider {
+	// В шаблон адреса подставляется одно имя, пакетный запрос из него не собрать
+	if strings.Contains(endpoint, "{name}") {
+		pk.BatchSize = 0
+	}
+	return &apiProvider{
+		name:      name,
+		endpoint:  endpoint,
+		fields:    pk.Fields,
+		parse:     pk.Parse,
+		batchSize: pk.BatchSize,
+	}
+}
+
+// ParseProviders строит список провайдеров из строки вида
+// "age=agify:https://api.agify.io/,gender=genderize:https://api.genderize.io/".
+// Имя провайдера можно опустить: "agify:https://api.agify.io/". Кроме зарегистрированных
+// форматов доступны kinds из ParseProviderKinds. Адрес может содержать {name} — тогда имя
+// подставляется в него, иначе добавляется параметром ?name=.
+func ParseProviders(spec string, kinds map[string]ProviderKind) ([]EnrichmentProvider, error) {
+	var providers []EnrichmentProvider
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kind, endpoint, ok := strings.Cut(item, ":")
+		if !ok || endpoint == "" {
+			return nil, fmt.Errorf("invalid provider %q: expected [name=]kind:endpoint", item)
+		}
+		name := kind
+		if n, k, ok := strings.Cut(kind, "="); ok {
+			name, kind = n, k
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate provider name %q", name)
+		}
+		seen[name] = true
+
+		if pk, ok := kinds[kind]; ok {
+			providers = append(providers, newAPIProvider(name, pk, endpoint))
+			continue
+		}
+		provider, err := NewProvider(name, kind, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, errors.New("no enrichment providers configured")
+	}
+	return providers, nil
+}
+
+type apiProvider struct {
+	name      string
+	endpoint  string
+	fields    []string
+	parse     ResponseParser
+	batchSize int
+}
+
+func (p *apiProvider) Name() string     { return p.name }
+func (p *apiProvider) Fields() []string { return p.fields }
+func (p *apiProvider) BatchSize() int   { return p.batchSize }
+
+func (p *apiProvider) RequestURL(name string) string {
+	if p.endpoint == "" {
+		return ""
+	}
+	if strings.Contains(p.endpoint, "{name}") {
+		return strings.ReplaceAll(p.endpoint, "{name}", url.QueryEscape(name))
+	}
+	return p.endpoint + "?name=" + url.QueryEscape(name)
+}
+
+func (p *apiProvider) BatchRequestURL(names []string) string {
+	if p.endpoint == "" {
+		return ""
+	}
+	query := make([]string, 0, len(names))
+	for _, name := range names {
+		query = append(query, "name[]="+url.QueryEscape(name))
+	}
+	return p.endpoint + "?" + strings.Join(query, "&")
+}
+
+func (p *apiProvider) Parse(body map[string]interface{}, person *models.Person) error {
+	return p.parse(body, person)
+}
+
+func parseAgify(res map[string]interface{}, person *models.Person) error {
+	age, ok := res["age"]
+	if !ok {
+		return errors.New("age field missing in response")
+	}
+
+	ageFloat, ok := age.(float64)
+	if !ok {
+		return fmt.Errorf("invalid age type: %T", age)
+	}
+
+	person.Age = int(ageFloat)
+	return nil
+}
+
+func parseGenderize(res map[string]interface{}, person *models.Person) error {
+	gender, ok := res["gender"]
+	if !ok {
+		return errors.New("gender field missing in response")
+	}
+
+	genderStr, ok := gender.(string)
+	if !ok {
+		return fmt.Errorf("invalid gender type: %T", gender)
+	}
+
+	person.Gender = genderStr
+	return nil
+}
+
+func parseNationalize(res map[string]interface{}, person *models.Person) error {
+	countries, ok := res["country"].([]interface{})
+	if !ok || len(countries) == 0 {
+		return errors.New("no country data in response")
+	}
+
+	country, ok := countries[0].(map[string]interface{})
+	if !ok {
+		return errors.New("invalid country format in response")
+	}
+
+	id, ok := country["country_id"].(string)
+	if !ok {
+		return errors.New("invalid country_id format in response")
+	}
+
+	person.Nationality = id
+	return nil
+}
+
+// jsonPath поле, которое берётся из ответа по пути path
+type jsonPath struct {
+	path  string
+	field string
+}
+
+// jsonPathParser разбирает ответ формата, объявленного в конфигурации
+func jsonPathParser(paths []jsonPath) ResponseParser {
+	return func(body map[string]interface{}, person *models.Person) error {
+		for _, p := range paths {
+			value, ok := lookupJSONPath(body, p.path)
+			if !ok || value == nil {
+				return fmt.Errorf("%s missing in response", p.path)
+			}
+			if err := setField(person, p.field, value); err != nil {
+				return fmt.Errorf("%s: %w", p.path, err)
+			}
+		}
+		return nil
+	}
+}
+
+func lookupJSONPath(body map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = body
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setField записывает значение из JSON в поле человека
+func setField(person *models.Person, field string, value interface{}) error {
+	if key, ok := strings.CutPrefix(field, AttributePrefix); ok {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			s = strconv.FormatBool(v)
+		default:
+			return fmt.Errorf("invalid %s type: %T", field, value)
+		}
+		if person.Attributes == nil {
+			person.Attributes = make(map[string]string)
+		}
+		person.Attributes[key] = s
+		return nil
+	}
+
+	if field == "age" {
+		age, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("invalid age type: %T", value)
+		}
+		person.Age = int(age)
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid %s type: %T", field, value)
+	}
+	switch field {
+	case "gender":
+		person.Gender = s
+	case "nationality":
+		person.Nationality = s
+	}
+	return nil
+}
+
+// isEnrichableField сообщает, может ли провайдер заполнять поле. Введённые пользователем
+// имя, фамилия и отчество провайдерам недоступны.
+func isEnrichableField(field string) bool {
+	switch field {
+	case "age", "gender", "nationality":
+		return true
+	}
+	key, ok := strings.CutPrefix(field, AttributePrefix)
+	return ok && isAttributeKey(key)
+}
+
+// isAttributeKey допускает ключи из строчных латинских букв, цифр и "_"
+func isAttributeKey(key string) bool {
+	if key == "" || len(key) > 64 {
+		return false
+	}
+	for _, c := range key {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// copyFields переносит из src в dst только перечисленные поля
+func copyFields(dst, src *models.Person, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "age":
+			dst.Age = src.Age
+		case "gender":
+			dst.Gender = src.Gender
+		case "nationality":
+			dst.Nationality = src.Nationality
+		default:
+			key, ok := strings.CutPrefix(field, AttributePrefix)
+			if value := src.Attributes[key]; ok && value != "" {
+				if dst.Attributes == nil {
+					dst.Attributes = make(map[string]string)
+				}
+				dst.Attributes[key] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "go-people-api/log"
+	"go-people-api/models"
+)
+
+// ErrQuotaExhausted дневная квота провайдера израсходована; запросы не отправляются до сброса
+var ErrQuotaExhausted = errors.New("provider quota exhausted")
+
+// DefaultQuotaThresholds доли оставшейся квоты, при переходе через которые пишется предупреждение
+var DefaultQuotaThresholds = []float64{0.2, 0.1, 0.01}
+
+// QuotaTracker запоминает квоту провайдера из заголовков X-Rate-Limit-Limit,
+// X-Rate-Limit-Remaining и X-Rate-Limit-Reset (секунды до сброса), которые отдают
+// agify, genderize и nationalize
+type QuotaTracker struct {
+	mu         sync.Mutex
+	provider   string
+	thresholds []float64
+	known      bool
+	limit      int
+	remaining  int
+	resetAt    time.Time
+	updatedAt  time.Time
+	// warned самый низкий порог, о котором уже предупредили в текущем окне
+	warned float64
+	// backoff пауза после 429, в котором нет времени сброса
+	backoff time.Duration
+	now     func() time.Time
+}
+
+func NewQuotaTracker(provider string, thresholds []float64) *QuotaTracker {
+	sorted := append([]float64(nil), thresholds...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	return &QuotaTracker{
+		provider:   provider,
+		thresholds: sorted,
+		warned:     1,
+		backoff:    DefaultProviderPolicy.QuotaBackoff,
+		now:        time.Now,
+	}
+}
+
+// SetBackoff задаёт паузу после 429 без времени сброса; вызывается при настройке сервиса
+func (q *QuotaTracker) SetBackoff(backoff time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.backoff = backoff
+}
+
+// Allow возвращает ErrQuotaExhausted, пока квота израсходована и не сброшена
+func (q *QuotaTracker) Allow() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.known && q.remaining <= 0 && q.now().Before(q.resetAt) {
+		return ErrQuotaExhausted
+	}
+	return nil
+}
+
+// Observe обновляет квоту по заголовкам ответа. Ответ 429 без заголовков считается
+// исчерпанием квоты до Retry-After, а без него — на время backoff.
+func (q *QuotaTracker) Observe(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Remaining"))
+	hasHeaders := err == nil
+	if !hasHeaders && resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	if !hasHeaders {
+		remaining = 0
+	}
+	if limit, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Limit")); err == nil {
+		q.limit = limit
+	}
+
+	wait := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if seconds, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Reset")); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	}
+	// Провайдер отказал, но не сказал, когда вернуться: без паузы воркеры продолжили бы запросы
+	if wait <= 0 && remaining <= 0 {
+		wait = q.backoff
+	}
+	resetAt := now.Add(wait)
+
+	// Квота выросла — началось новое окно, предупреждения снова нужны
+	if q.known && remaining > q.remaining {
+		q.warned = 1
+	}
+	q.known = true
+	q.remaining = remaining
+	q.resetAt = resetAt
+	q.updatedAt = now
+
+	q.warn()
+}
+
+// warn предупреждает о самом низком пройденном пороге и об исчерпании квоты,
+// каждый раз не чаще одного раза за окно; вызывается под блокировкой
+func (q *QuotaTracker) warn() {
+	logger := log.WithContext(context.Background())
+	if q.remaining <= 0 {
+		if q.warned > 0 {
+			q.warned = 0
+			logger.Warnf("Enrichment provider %s quota exhausted until %s", q.provider, q.resetAt.Format(time.RFC3339))
+		}
+		return
+	}
+	if q.limit <= 0 {
+		return
+	}
+
+	left := float64(q.remaining) / float64(q.limit)
+	crossed := q.warned
+	for _, threshold := range q.thresholds {
+		if left <= threshold && threshold < crossed {
+			crossed = threshold
+		}
+	}
+	if crossed < q.warned {
+		q.warned = crossed
+		logger.Warnf("Enrichment provider %s quota below %g%%: %d of %d requests left, resets at %s",
+			q.provider, crossed*100, q.remaining, q.limit, q.resetAt.Format(time.RFC3339))
+	}
+}
+
+// Status состояние квоты для административного API
+func (q *QuotaTracker) Status() models.QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := models.QuotaStatus{Provider: q.provider}
+	if !q.known {
+		return status
+	}
+	remaining, resetAt, updatedAt := q.remaining, q.resetAt, q.updatedAt
+	status.Limit = q.limit
+	status.Remaining = &remaining
+	status.ResetAt = &resetAt
+	status.UpdatedAt = &updatedAt
+	status.Exhausted = remaining <= 0 && q.now().Before(resetAt)
+	return status
+}
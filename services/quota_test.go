@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrichmentService_StopsWhenQuotaExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining := 2 - calls.Add(1)
+		w.Header().Set("X-Rate-Limit-Limit", "1000")
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(int(remaining)))
+		w.Header().Set("X-Rate-Limit-Reset", "3600")
+		_, _ = w.Write([]byte(`{"age": 41}`))
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(age)
+	_ = service.SetProviderPolicy("age", fastPolicy(3, 1))
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Enrich(context.Background(), "Oleg"); err != nil {
+			t.Fatalf("call %d: unexpected error %v", i, err)
+		}
+	}
+
+	quota := service.ProviderQuotas()[0]
+	if !quota.Exhausted || quota.Limit != 1000 || *quota.Remaining != 0 || quota.ResetAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatalf("expected exhausted quota, got %+v", quota)
+	}
+
+	_, err := service.fetchProvider(context.Background(), age, "Oleg")
+	if !errors.Is(err, ErrQuotaExhausted) || calls.Load() != 2 {
+		t.Fatalf("expected no request after exhaustion, got %v after %d calls", err, calls.Load())
+	}
+	// Исчерпанная квота не размыкает автомат
+	if status := service.ProviderStatuses()[0]; status.Breaker != string(BreakerClosed) {
+		t.Errorf("breaker must stay closed, got %s", status.Breaker)
+	}
+}
+
+func TestQuotaTracker_ResetAndThresholds(t *testing.T) {
+	now := time.Now()
+	quota := NewQuotaTracker("age", []float64{0.1, 0.5})
+	quota.now = func() time.Time { return now }
+
+	observe := func(status, remaining int, reset string) {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if remaining >= 0 {
+			resp.Header.Set("X-Rate-Limit-Limit", "100")
+			resp.Header.Set("X-Rate-Limit-Remaining", strconv.Itoa(remaining))
+			resp.Header.Set("X-Rate-Limit-Reset", reset)
+		}
+		quota.Observe(resp)
+	}
+
+	observe(http.StatusOK, 40, "60")
+	if quota.warned != 0.5 {
+		t.Errorf("expected 50%% warning, got %v", quota.warned)
+	}
+	observe(http.StatusOK, 5, "60")
+	if quota.warned != 0.1 {
+		t.Errorf("expected 10%% warning, got %v", quota.warned)
+	}
+
+	// 429 без заголовков квоты блокирует провайдера до Retry-After
+	quota.Observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"30"}}})
+	if err := quota.Allow(); !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("expected exhausted quota, got %v", err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := quota.Allow(); err != nil {
+		t.Fatalf("expected quota to reset, got %v", err)
+	}
+	observe(http.StatusOK, 99, "86400")
+	if quota.warned != 1 || quota.Status().Exhausted {
+		t.Errorf("expected warnings to be re-armed after reset, got %v", quota.warned)
+	}
+}
+
+func TestQuotaTracker_BackoffWithoutResetHint(t *testing.T) {
+	now := time.Now()
+	quota := NewQuotaTracker("age", nil)
+	quota.now = func() time.Time { return now }
+	quota.SetBackoff(2 * time.Minute)
+
+	// 429 без X-Rate-Limit-* и Retry-After: провайдер всё равно ставится на паузу
+	quota.Observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if err := quota.Allow(); !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("expected exhausted quota, got %v", err)
+	}
+	if status := quota.Status(); !status.ResetAt.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("expected reset after backoff, got %v", status.ResetAt)
+	}
+
+	now = now.Add(2*time.Minute + time.Second)
+	if err := quota.Allow(); err != nil {
+		t.Errorf("expected quota to reset after backoff, got %v", err)
+	}
+}
+
+func TestEnrichmentService_PausesAfterBare429(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(age)
+	policy := fastPolicy(3, 10)
+	policy.QuotaBackoff = time.Hour
+	_ = service.SetProviderPolicy("age", policy)
+
+	for i := 0; i < 3; i++ {
+		_, _ = service.fetchProvider(context.Background(), age, "Oleg")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single request before the pause, got %d", calls.Load())
+	}
+}
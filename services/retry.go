@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-people-api/metrics"
+)
+
+// RetryPolicy политика повторов запросов к провайдеру
+type RetryPolicy struct {
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	AttemptTimeout time.Duration
+}
+
+// ProviderPolicy настройки устойчивости для одного провайдера
+type ProviderPolicy struct {
+	Retry   RetryPolicy
+	Breaker BreakerSettings
+	// QuotaBackoff пауза после 429 без X-Rate-Limit-Reset и Retry-After
+	QuotaBackoff time.Duration
+}
+
+var DefaultProviderPolicy = ProviderPolicy{
+	Retry: RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       time.Second,
+		AttemptTimeout: 800 * time.Millisecond,
+	},
+	Breaker: BreakerSettings{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	},
+	QuotaBackoff: time.Minute,
+}
+
+// apiError ответ провайдера с неуспешным статусом
+type apiError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("API returned status: %d", e.StatusCode)
+}
+
+// backoff возвращает задержку перед повтором attempt (с нуля) с полным джиттером
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// attemptOutcome результат попытки для метрик
+func attemptOutcome(err error) string {
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return metrics.OutcomeRateLimited
+	}
+	return metrics.OutcomeError
+}
+
+func isRetryable(err error) bool {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	// Сетевые ошибки и таймауты отдельной попытки
+	return true
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP-дата)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// fetchWithRetry выполняет запрос к провайдеру с повторами через его автомат
+// и декодирует JSON-ответ в out
+func (s *EnrichmentService) fetchWithRetry(ctx context.Context, state *providerState, url string, out interface{}) error {
+	policy := state.policy.Retry
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := policy.backoff(attempt - 1)
+			var apiErr *apiError
+			if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+				delay = apiErr.RetryAfter
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return lastErr
+			case <-time.After(delay):
+			}
+		}
+
+		// Исчерпанная квота — не сбой провайдера, автомат не трогаем
+		if err := state.quota.Allow(); err != nil {
+			metrics.ObserveEnrichment(state.name, metrics.OutcomeQuotaExhausted, 0)
+			if lastErr != nil {
+				return fmt.Errorf("%w (last error: %v)", err, lastErr)
+			}
+			return err
+		}
+		if err := state.breaker.Allow(); err != nil {
+			metrics.ObserveEnrichment(state.name, metrics.OutcomeCircuitOpen, 0)
+			if lastErr != nil {
+				return fmt.Errorf("%w (last error: %v)", err, lastErr)
+			}
+			return err
+		}
+
+		started := time.Now()
+		err := s.fetchAttempt(ctx, state.quota, url, policy.AttemptTimeout, out)
+		metrics.ObserveEnrichment(state.name, attemptOutcome(err), time.Since(started))
+		if err == nil {
+			state.breaker.Success()
+			return nil
+		}
+		if ctx.Err() != nil {
+			// Общий дедлайн обогащения или отмена клиентом — провайдер не виноват
+			state.breaker.Release()
+			return err
+		}
+
+		lastErr = err
+		if !isRetryable(err) {
+			// Провайдер ответил, просто не на этот запрос
+			state.breaker.Success()
+			break
+		}
+		state.breaker.Failure()
+	}
+
+	return lastErr
+}
+
+func (s *EnrichmentService) fetchAttempt(ctx context.Context, quota *QuotaTracker, url string, timeout time.Duration, out interface{}) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.fetchAPI(ctx, quota, url, out)
+}
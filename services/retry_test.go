@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastPolicy(attempts, threshold int) ProviderPolicy {
+	return ProviderPolicy{
+		Retry: RetryPolicy{
+			MaxAttempts:    attempts,
+			BaseDelay:      time.Millisecond,
+			MaxDelay:       5 * time.Millisecond,
+			AttemptTimeout: 200 * time.Millisecond,
+		},
+		Breaker: BreakerSettings{FailureThreshold: threshold, OpenTimeout: time.Minute},
+	}
+}
+
+func TestEnrichmentService_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"age": 41}`))
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(age)
+	_ = service.SetProviderPolicy("age", fastPolicy(3, 10))
+
+	person, err := service.Enrich(context.Background(), "Oleg")
+	if err != nil {
+		t.Fatalf("expected success after retries, got: %v", err)
+	}
+	if person.Age != 41 || calls.Load() != 3 {
+		t.Errorf("expected age 41 after 3 calls, got %d after %d", person.Age, calls.Load())
+	}
+}
+
+func TestEnrichmentService_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(age)
+	_ = service.SetProviderPolicy("age", fastPolicy(3, 1))
+
+	if _, err := service.Enrich(context.Background(), "Oleg"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single call, got %d", calls.Load())
+	}
+	if status := service.ProviderStatuses()[0]; status.Breaker != string(BreakerClosed) {
+		t.Errorf("client errors must not open the breaker, got %s", status.Breaker)
+	}
+}
+
+func TestEnrichmentService_BreakerShortCircuits(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	age, _ := NewProvider("age", "agify", server.URL)
+	service := NewEnrichmentServiceWithProviders(age)
+	_ = service.SetProviderPolicy("age", fastPolicy(2, 2))
+
+	_, _ = service.Enrich(context.Background(), "Oleg")
+	_, err := service.Enrich(context.Background(), "Oleg")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected breaker to stop calls after 2 failures, got %d calls", calls.Load())
+	}
+
+	status := service.ProviderStatuses()[0]
+	if status.Breaker != string(BreakerOpen) || status.OpenedAt == nil {
+		t.Errorf("expected open breaker, got %+v", status)
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open breaker, got %v", err)
+	}
+
+	now = now.Add(2 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected only one probe in half-open state, got %v", err)
+	}
+
+	b.Success()
+	if state, failures, _ := b.State(); state != BreakerClosed || failures != 0 {
+		t.Errorf("expected closed breaker after successful probe, got %s/%d", state, failures)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3"); d != 3*time.Second {
+		t.Errorf("expected 3s, got %s", d)
+	}
+	if d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); d <= 0 || d > time.Minute {
+		t.Errorf("unexpected delay for HTTP date: %s", d)
+	}
+	if d := parseRetryAfter("soon"); d != 0 {
+		t.Errorf("expected 0 for invalid value, got %s", d)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-people-api/config"
+	"go-people-api/db"
+	"go-people-api/models"
+	"go-people-api/repository"
+	"go-people-api/transfer"
+)
+
+// importBatchSize сколько строк сохраняется одной транзакцией, как в POST /people/import
+const importBatchSize = 1000
+
+const (
+	importUsage = `usage: go-people-api import [-file people.csv] [-format csv|ndjson] [-dry-run]`
+	exportUsage = `usage: go-people-api export [-file people.csv] [-format csv|ndjson] [-name <s>] [-surname <s>] [-gender <g>] [-nationality XX] [-age-from N] [-age-to N] [-include-deleted] [-sort <field>]`
+)
+
+// runImportCommand загружает людей из файла или stdin без обогащения. Некорректные
+// строки попадают в отчёт, остальные сохраняются; при ошибках в строках код завершения 1.
+func runImportCommand(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "-", "файл; - — stdin")
+	formatName := flags.String("format", "", "csv или ndjson; по умолчанию по расширению файла")
+	dryRun := flags.Bool("dry-run", false, "только проверить файл, ничего не сохраняя")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+	format, err := fileFormat(*formatName, *file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 2
+	}
+	if err := connectDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "database:", err)
+		return 1
+	}
+
+	in := io.Reader(os.Stdin)
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import:", err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := importPeople(ctx, repository.NewPostgresPersonRepository(db.DB), transfer.NewReader(in, format), *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	if code := printJSON(report); code != 0 {
+		return code
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// importPeople читает все строки, проверяет их и сохраняет пакетами
+func importPeople(ctx context.Context, people repository.PersonRepository, reader transfer.Reader, dryRun bool) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: dryRun, Errors: []models.ImportRowError{}}
+	var (
+		valid []*models.Person
+		lines []int
+	)
+	for {
+		person, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *transfer.RowError
+		if errors.As(err, &rowErr) {
+			report.Total++
+			report.Errors = append(report.Errors, importRowError(rowErr.Line, "parse_error", "Invalid row", rowErr.Err))
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Total++
+		if err := validatePerson(person); err != nil {
+			report.Errors = append(report.Errors, importRowError(line, "validation_error", "Invalid input data", err))
+			continue
+		}
+		valid = append(valid, person)
+		lines = append(lines, line)
+	}
+
+	if dryRun {
+		report.Imported = len(valid)
+	} else {
+		for start := 0; start < len(valid); start += importBatchSize {
+			end := min(start+importBatchSize, len(valid))
+			createErrs, err := people.CreateBatch(ctx, valid[start:end])
+			if err != nil {
+				return report, err
+			}
+			for i, createErr := range createErrs {
+				if createErr != nil {
+					report.Errors = append(report.Errors, importRowError(lines[start+i], "database_error", "Failed to create person record", createErr))
+					continue
+				}
+				report.Imported++
+			}
+		}
+	}
+	report.Failed = len(report.Errors)
+	return report, nil
+}
+
+func importRowError(line int, code, message string, err error) models.ImportRowError {
+	return models.ImportRowError{
+		Line:  line,
+		Error: models.ErrorResponse{Error: code, Message: message, Details: err.Error()},
+	}
+}
+
+// runExportCommand выгружает людей с фильтрами и сортировкой GET /people/export
+// в файл или stdout. Прерывается по Ctrl+C.
+func runExportCommand(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "-", "файл; - — stdout")
+	formatName := flags.String("format", "", "csv или ndjson; по умолчанию по расширению файла")
+	var filter models.PersonFilter
+	flags.StringVar(&filter.Name, "name", "", "имя (подстрока)")
+	flags.StringVar(&filter.Surname, "surname", "", "фамилия (подстрока)")
+	flags.StringVar(&filter.Gender, "gender", "", "пол")
+	flags.StringVar(&filter.Nationality, "nationality", "", "код страны")
+	flags.Func("age-from", "минимальный возраст", intPointer(&filter.AgeFrom))
+	flags.Func("age-to", "максимальный возраст", intPointer(&filter.AgeTo))
+	flags.BoolVar(&filter.IncludeDeleted, "include-deleted", false, "включить удалённых")
+	flags.StringVar(&filter.Sort, "sort", "", "поле сортировки, -поле — по убыванию")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+	format, err := fileFormat(*formatName, *file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+	if err := connectDB(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "database:", err)
+		return 1
+	}
+
+	out := io.Writer(os.Stdout)
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+	buffered := bufio.NewWriter(out)
+	writer := transfer.NewWriter(buffered, format)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	written := 0
+	err = repository.NewPostgresPersonRepository(db.DB).Stream(ctx, filter, func(person *models.Person) error {
+		written++
+		return writer.Write(person)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "exported %d people as %s\n", written, format)
+	return 0
+}
+
+// fileFormat берёт формат из флага, иначе из расширения файла; по умолчанию CSV
+func fileFormat(name, file string) (transfer.Format, error) {
+	if name == "" && file != "-" {
+		name = strings.TrimPrefix(filepath.Ext(file), ".")
+		if _, err := transfer.ParseFormat(name); err != nil {
+			name = ""
+		}
+	}
+	return transfer.ParseFormat(name)
+}
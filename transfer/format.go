@@ -0,0 +1,55 @@
+// Package transfer читает и пишет людей в форматах CSV и NDJSON для импорта и выгрузки
+package transfer
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Format формат файла импорта/выгрузки
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat разбирает название формата; пустая строка означает CSV
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q: expected csv or ndjson", s)
+}
+
+// FormatFromContentType определяет формат по заголовку Content-Type
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, true
+	}
+	return "", false
+}
+
+// ContentType заголовок Content-Type для выгрузки
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension расширение файла выгрузки
+func (f Format) Extension() string {
+	return string(f)
+}
@@ -0,0 +1,162 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-people-api/models"
+)
+
+// maxLineSize максимальная длина строки NDJSON
+const maxLineSize = 1 << 20
+
+// RowError ошибка разбора одной строки; чтение следующих строк можно продолжать
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader читает людей из файла импорта
+type Reader interface {
+	// Read возвращает следующую запись и номер её строки.
+	// io.EOF означает конец файла, *RowError — ошибку только в текущей строке.
+	Read() (*models.Person, int, error)
+}
+
+func NewReader(r io.Reader, format Format) Reader {
+	if format == FormatNDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}
+	}
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	return &csvReader{r: cr}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// importColumns колонки, которые учитываются при импорте; остальные колонки выгрузки
+// (id, enrichment_status, даты) допускаются, но игнорируются
+var importColumns = map[string]bool{
+	"name": true, "surname": true, "patronymic": true,
+	"gender": true, "age": true, "nationality": true,
+}
+
+var exportOnlyColumns = map[string]bool{
+	"id": true, "enrichment_status": true, "created_at": true, "updated_at": true, "deleted_at": true,
+}
+
+func (r *csvReader) readHeader() error {
+	header, err := r.r.Read()
+	if err == io.EOF {
+		return errors.New("empty file: header row is required")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+
+	r.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !importColumns[name] && !exportOnlyColumns[name] {
+			return fmt.Errorf("invalid header: unknown column %q", name)
+		}
+		if _, ok := r.columns[name]; ok {
+			return fmt.Errorf("invalid header: duplicate column %q", name)
+		}
+		r.columns[name] = i
+	}
+	for _, name := range []string{"name", "surname"} {
+		if _, ok := r.columns[name]; !ok {
+			return fmt.Errorf("invalid header: column %q is required", name)
+		}
+	}
+	return nil
+}
+
+func (r *csvReader) Read() (*models.Person, int, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, 1, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ := r.r.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	person := &models.Person{
+		Name:        field("name"),
+		Surname:     field("surname"),
+		Patronymic:  field("patronymic"),
+		Gender:      field("gender"),
+		Nationality: field("nationality"),
+	}
+	if age := field("age"); age != "" {
+		if person.Age, err = strconv.Atoi(age); err != nil {
+			return nil, line, &RowError{Line: line, Err: fmt.Errorf("invalid age %q", age)}
+		}
+	}
+	return person, line, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Read() (*models.Person, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var person models.Person
+		if err := json.Unmarshal(data, &person); err != nil {
+			return nil, r.line, &RowError{Line: r.line, Err: err}
+		}
+		// Служебные поля задаются при сохранении
+		person.ID = 0
+		person.EnrichmentStatus = ""
+		person.DeletedAt = nil
+		return &person, r.line, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, r.line + 1, err
+	}
+	return nil, r.line, io.EOF
+}
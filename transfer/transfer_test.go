@@ -0,0 +1,94 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go-people-api/models"
+)
+
+func readAll(t *testing.T, r Reader) ([]*models.Person, []*RowError) {
+	t.Helper()
+	var (
+		people  []*models.Person
+		rowErrs []*RowError
+	)
+	for {
+		person, _, err := r.Read()
+		if err == io.EOF {
+			return people, rowErrs
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		people = append(people, person)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	people := []*models.Person{
+		{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 30, Gender: "male", Nationality: "RU"},
+		{ID: 2, Name: "Anna, Maria", Surname: "O\"Neil", Patronymic: "Ivanovna"},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatNDJSON} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, format)
+		for _, p := range people {
+			if err := w.Write(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, rowErrs := readAll(t, NewReader(&buf, format))
+		if len(rowErrs) != 0 || len(got) != 2 {
+			t.Fatalf("%s: unexpected result %+v, %v", format, got, rowErrs)
+		}
+		if got[0].Age != 30 || got[0].Nationality != "RU" || got[1].Name != "Anna, Maria" || got[1].Surname != `O"Neil` {
+			t.Errorf("%s: unexpected people %+v %+v", format, got[0], got[1])
+		}
+		if got[0].ID != 0 {
+			t.Errorf("%s: id must not be imported", format)
+		}
+	}
+}
+
+func TestCSVReader_RowErrors(t *testing.T) {
+	input := "name,surname,age\nIvan,Petrov,30\nAnna,Smirnova,old\nOlga\nPetr,Ivanov,\n"
+	people, rowErrs := readAll(t, NewReader(strings.NewReader(input), FormatCSV))
+	if len(people) != 2 || len(rowErrs) != 2 {
+		t.Fatalf("unexpected result: %+v, %v", people, rowErrs)
+	}
+	if rowErrs[0].Line != 3 || rowErrs[1].Line != 4 {
+		t.Errorf("unexpected error lines: %v", rowErrs)
+	}
+}
+
+func TestCSVReader_InvalidHeader(t *testing.T) {
+	for _, input := range []string{"", "name,age\n", "name,surname,password\n"} {
+		_, _, err := NewReader(strings.NewReader(input), FormatCSV).Read()
+		var rowErr *RowError
+		if err == nil || err == io.EOF || errors.As(err, &rowErr) {
+			t.Errorf("%q: expected header error, got %v", input, err)
+		}
+	}
+}
+
+func TestNDJSONReader_RowErrors(t *testing.T) {
+	input := "{\"name\":\"Ivan\",\"surname\":\"Petrov\"}\n\n{broken\n{\"name\":\"Anna\",\"surname\":\"Smirnova\",\"age\":25}\n"
+	people, rowErrs := readAll(t, NewReader(strings.NewReader(input), FormatNDJSON))
+	if len(people) != 2 || len(rowErrs) != 1 || rowErrs[0].Line != 3 || people[1].Age != 25 {
+		t.Fatalf("unexpected result: %+v, %v", people, rowErrs)
+	}
+}
@@ -0,0 +1,79 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"go-people-api/models"
+)
+
+// csvColumns колонки выгрузки в CSV
+var csvColumns = []string{
+	"id", "name", "surname", "patronymic", "gender", "age", "nationality",
+	"enrichment_status", "created_at", "updated_at", "deleted_at",
+}
+
+// Writer пишет людей в выбранном формате
+type Writer interface {
+	Write(person *models.Person) error
+	// Flush дописывает буферизованные данные в нижележащий поток
+	Flush() error
+}
+
+func NewWriter(w io.Writer, format Format) Writer {
+	if format == FormatNDJSON {
+		return &ndjsonWriter{enc: json.NewEncoder(w)}
+	}
+	cw := csv.NewWriter(w)
+	// Ошибка записи заголовка вернётся из Flush
+	_ = cw.Write(csvColumns)
+	return &csvWriter{w: cw, record: make([]string, len(csvColumns))}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (w *csvWriter) Write(p *models.Person) error {
+	age := ""
+	if p.Age > 0 {
+		age = strconv.Itoa(p.Age)
+	}
+
+	w.record[0] = strconv.Itoa(p.ID)
+	w.record[1] = p.Name
+	w.record[2] = p.Surname
+	w.record[3] = p.Patronymic
+	w.record[4] = p.Gender
+	w.record[5] = age
+	w.record[6] = p.Nationality
+	w.record[7] = p.EnrichmentStatus
+	w.record[8] = p.CreatedAt.Format(time.RFC3339)
+	w.record[9] = p.UpdatedAt.Format(time.RFC3339)
+	w.record[10] = ""
+	if p.DeletedAt != nil {
+		w.record[10] = p.DeletedAt.Format(time.RFC3339)
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(p *models.Person) error {
+	return w.enc.Encode(p)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}